@@ -0,0 +1,385 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/github"
+	"golang.org/x/xerrors"
+)
+
+const (
+	SessionCookie      = "pipelinerun_logs_session"
+	LoginStateCookie   = "pipelinerun_logs_login"
+	SessionDuration    = 12 * time.Hour
+	LoginStateDuration = 10 * time.Minute
+	GitHubAPIURL       = "https://api.github.com"
+	// MinSessionKeyLength is the minimum length of the session secret.
+	MinSessionKeyLength = 32
+)
+
+// Authenticator signs users in with an OAuth identity provider.
+type Authenticator interface {
+	// AuthCodeURL returns the url of the provider's login page, which
+	// redirects back to the callback with state and a code.
+	AuthCodeURL(state string) string
+	// Authenticate exchanges a code for the identity of the user. An error
+	// is returned if the user is not allowed to read private logs.
+	Authenticate(ctx context.Context, code string) (string, error)
+}
+
+// NewAuthenticator returns the Authenticator selected by the -auth flag,
+// or nil if authentication is disabled.
+func NewAuthenticator(ctx context.Context, conf *config.Config) (Authenticator, error) {
+	if conf.Auth == "" {
+		return nil, nil
+	}
+	secret, err := readSecretFile(conf.OAuthClientSecretFile)
+	if err != nil {
+		return nil, err
+	}
+	oauth := &oauth2.Config{
+		ClientID:     conf.OAuthClientID,
+		ClientSecret: secret,
+		RedirectURL:  conf.OAuthRedirectURL,
+	}
+	switch conf.Auth {
+	case config.AuthOIDC:
+		provider, err := oidc.NewProvider(ctx, conf.OIDCIssuerURL)
+		if err != nil {
+			return nil, xerrors.Errorf("error discovering oidc provider: %w", err)
+		}
+		oauth.Endpoint = provider.Endpoint()
+		oauth.Scopes = []string{oidc.ScopeOpenID, "email"}
+		return &oidcAuthenticator{
+			oauth:       oauth,
+			verifier:    provider.Verifier(&oidc.Config{ClientID: conf.OAuthClientID}),
+			emailDomain: conf.OIDCEmailDomain,
+		}, nil
+	case config.AuthGitHub:
+		oauth.Endpoint = github.Endpoint
+		oauth.Scopes = []string{"read:org"}
+		return &githubAuthenticator{oauth: oauth, org: conf.GitHubOrg, apiURL: GitHubAPIURL}, nil
+	}
+	return nil, fmt.Errorf("unknown authentication %q", conf.Auth)
+}
+
+// readSecretFile returns the trimmed contents of a file holding a secret.
+func readSecretFile(path string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", xerrors.Errorf("error reading secret: %w", err)
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// oidcAuthenticator signs users in with an OpenID Connect provider. If
+// emailDomain is set only users with a verified email address in that
+// domain are allowed in.
+type oidcAuthenticator struct {
+	oauth       *oauth2.Config
+	verifier    *oidc.IDTokenVerifier
+	emailDomain string
+}
+
+func (a *oidcAuthenticator) AuthCodeURL(state string) string {
+	return a.oauth.AuthCodeURL(state)
+}
+
+func (a *oidcAuthenticator) Authenticate(ctx context.Context, code string) (string, error) {
+	token, err := a.oauth.Exchange(ctx, code)
+	if err != nil {
+		return "", xerrors.Errorf("error exchanging code: %w", err)
+	}
+	raw, ok := token.Extra("id_token").(string)
+	if !ok {
+		return "", errors.New("no id_token in token response")
+	}
+	idToken, err := a.verifier.Verify(ctx, raw)
+	if err != nil {
+		return "", xerrors.Errorf("error verifying id token: %w", err)
+	}
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return "", xerrors.Errorf("error reading id token claims: %w", err)
+	}
+	if err := checkEmailDomain(claims.Email, claims.EmailVerified, a.emailDomain); err != nil {
+		return "", err
+	}
+	if claims.Email != "" {
+		return claims.Email, nil
+	}
+	return idToken.Subject, nil
+}
+
+// checkEmailDomain returns an error if domain is set and email is not a
+// verified address in it.
+func checkEmailDomain(email string, verified bool, domain string) error {
+	if domain == "" {
+		return nil
+	}
+	if !verified || !strings.HasSuffix(strings.ToLower(email), "@"+strings.ToLower(domain)) {
+		return fmt.Errorf("user %q is not a verified member of %s", email, domain)
+	}
+	return nil
+}
+
+// githubAuthenticator signs users in with GitHub, allowing members of org.
+type githubAuthenticator struct {
+	oauth  *oauth2.Config
+	org    string
+	apiURL string
+}
+
+func (a *githubAuthenticator) AuthCodeURL(state string) string {
+	return a.oauth.AuthCodeURL(state)
+}
+
+func (a *githubAuthenticator) Authenticate(ctx context.Context, code string) (string, error) {
+	token, err := a.oauth.Exchange(ctx, code)
+	if err != nil {
+		return "", xerrors.Errorf("error exchanging code: %w", err)
+	}
+	client := a.oauth.Client(ctx, token)
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := a.get(ctx, client, "/user", &user); err != nil {
+		return "", err
+	}
+	var membership struct {
+		State string `json:"state"`
+	}
+	if err := a.get(ctx, client, "/user/memberships/orgs/"+url.PathEscape(a.org), &membership); err != nil || membership.State != "active" {
+		return "", fmt.Errorf("user %q is not a member of %s", user.Login, a.org)
+	}
+	return user.Login, nil
+}
+
+// get decodes the JSON response of a GitHub API request.
+func (a *githubAuthenticator) get(ctx context.Context, client *http.Client, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.apiURL+path, nil)
+	if err != nil {
+		return xerrors.Errorf("error building github request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("error querying github: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error querying github %s: unexpected status %s", path, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return xerrors.Errorf("error decoding github response: %w", err)
+	}
+	return nil
+}
+
+// cookieSigner signs cookie values with an HMAC so that they cannot be
+// forged, and expires them server side. The HMAC covers the cookie name so
+// that a value signed for one cookie is rejected as another.
+type cookieSigner struct {
+	key []byte
+}
+
+type signedValue struct {
+	Value   string `json:"v"`
+	Expires int64  `json:"e"`
+}
+
+func (c *cookieSigner) mac(name, payload string) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(name + "=" + payload))
+	return h.Sum(nil)
+}
+
+// sign returns a value of the cookie name holding value until expires.
+func (c *cookieSigner) sign(name, value string, expires time.Time) string {
+	b, _ := json.Marshal(&signedValue{Value: value, Expires: expires.Unix()})
+	payload := base64.RawURLEncoding.EncodeToString(b)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(c.mac(name, payload))
+}
+
+// verify returns the value of a signed value of the cookie name, or an
+// error if it was tampered with, signed for another cookie or has expired.
+func (c *cookieSigner) verify(name, cookie string, now time.Time) (string, error) {
+	i := strings.LastIndex(cookie, ".")
+	if i < 0 {
+		return "", errors.New("invalid signed value")
+	}
+	payload, sig := cookie[:i], cookie[i+1:]
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, c.mac(name, payload)) {
+		return "", errors.New("invalid signature")
+	}
+	b, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return "", xerrors.Errorf("invalid signed value: %w", err)
+	}
+	var v signedValue
+	if err := json.Unmarshal(b, &v); err != nil {
+		return "", xerrors.Errorf("invalid signed value: %w", err)
+	}
+	if now.Unix() > v.Expires {
+		return "", errors.New("signed value expired")
+	}
+	return v.Value, nil
+}
+
+// EnableAuth requires users to sign in with auth before reading logs of
+// namespaces not listed in -public-namespaces. Sessions are signed with
+// sessionKey.
+func (s *Server) EnableAuth(auth Authenticator, sessionKey []byte) {
+	s.auth = auth
+	s.cookies = &cookieSigner{key: sessionKey}
+}
+
+// sessionUser returns the signed in user of a request.
+func (s *Server) sessionUser(r *http.Request) (string, bool) {
+	c, err := r.Cookie(SessionCookie)
+	if err != nil {
+		return "", false
+	}
+	user, err := s.cookies.verify(c.Name, c.Value, time.Now())
+	if err != nil {
+		return "", false
+	}
+	return user, true
+}
+
+// canRead reports whether the user of a request may read the logs of a
+// namespace.
+func (s *Server) canRead(r *http.Request, namespace string) bool {
+	if s.auth == nil {
+		return true
+	}
+	if _, ok := s.public[namespace]; ok {
+		return true
+	}
+	_, ok := s.sessionUser(r)
+	return ok
+}
+
+// authorize checks that the user of a request may read the logs of a
+// namespace. Anonymous requests for the html pages of private namespaces
+// are redirected to sign in, other requests receive a 401.
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request, namespace string) bool {
+	if s.canRead(r, namespace) {
+		return true
+	}
+	if format, _ := negotiateFormat(r); r.URL.Path == "/" && format == FormatHTML {
+		http.Redirect(w, r, "/auth/login?redirect="+url.QueryEscape(r.URL.RequestURI()), http.StatusFound)
+		return false
+	}
+	log.Printf("disallowing anonymous request for logs in namespace %q", namespace)
+	w.WriteHeader(http.StatusUnauthorized)
+	return false
+}
+
+// isLocalRedirect reports whether a redirect target stays on this app.
+func isLocalRedirect(target string) bool {
+	return strings.HasPrefix(target, "/") && !strings.HasPrefix(target, "//") && !strings.HasPrefix(target, "/\\")
+}
+
+// secureCookies reports whether cookies should only be sent over https.
+func (s *Server) secureCookies() bool {
+	return strings.HasPrefix(s.conf.OAuthRedirectURL, "https://")
+}
+
+// login redirects to the identity provider, remembering the page to
+// return to in a short lived cookie.
+func (s *Server) login(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	if !isLocalRedirect(redirect) {
+		redirect = "/"
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		log.Printf("error generating login state: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	state := base64.RawURLEncoding.EncodeToString(b)
+	expires := time.Now().Add(LoginStateDuration)
+	http.SetCookie(w, &http.Cookie{
+		Name:     LoginStateCookie,
+		Value:    s.cookies.sign(LoginStateCookie, state+" "+redirect, expires),
+		Path:     "/auth/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, s.auth.AuthCodeURL(state), http.StatusFound)
+}
+
+// callback completes a login, starting a session for the user if they
+// are allowed to read private logs.
+func (s *Server) callback(w http.ResponseWriter, r *http.Request) {
+	c, err := r.Cookie(LoginStateCookie)
+	if err != nil {
+		log.Printf("login callback without state cookie")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	value, err := s.cookies.verify(c.Name, c.Value, time.Now())
+	if err != nil {
+		log.Printf("invalid login state: %v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	parts := strings.SplitN(value, " ", 2)
+	if len(parts) != 2 || subtle.ConstantTimeCompare([]byte(parts[0]), []byte(r.URL.Query().Get("state"))) != 1 {
+		log.Printf("login state mismatch")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: LoginStateCookie, Path: "/auth/", MaxAge: -1})
+
+	user, err := s.auth.Authenticate(r.Context(), r.URL.Query().Get("code"))
+	if err != nil {
+		log.Printf("login failed: %v", err)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	log.Printf("user %q signed in", user)
+	expires := time.Now().Add(SessionDuration)
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionCookie,
+		Value:    s.cookies.sign(SessionCookie, user, expires),
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   s.secureCookies(),
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, parts[1], http.StatusFound)
+}
+
+// logout ends the session of a user.
+func (s *Server) logout(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: SessionCookie, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/", http.StatusFound)
+}
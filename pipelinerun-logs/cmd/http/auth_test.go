@@ -0,0 +1,352 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+	"golang.org/x/oauth2"
+)
+
+var testSessionKey = []byte("0123456789abcdef0123456789abcdef")
+
+// staticAuthenticator signs in user, or fails with err.
+type staticAuthenticator struct {
+	user string
+	err  error
+}
+
+func (a *staticAuthenticator) AuthCodeURL(state string) string {
+	return "https://idp.example.com/authorize?state=" + url.QueryEscape(state)
+}
+
+func (a *staticAuthenticator) Authenticate(ctx context.Context, code string) (string, error) {
+	return a.user, a.err
+}
+
+func newAuthTestServer(auth Authenticator) *Server {
+	conf := &config.Config{
+		Project:           "FooProject",
+		Namespace:         "default,test-pods",
+		PublicNamespaces:  "default",
+		PageSize:          10000,
+		StreamIdleTimeout: 10 * time.Minute,
+		IndexLookback:     100 * 365 * 24 * time.Hour,
+		OAuthRedirectURL:  "https://logs.example.com/auth/callback",
+	}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+	s.EnableAuth(auth, testSessionKey)
+	return s
+}
+
+func TestCookieSigner(t *testing.T) {
+	c := &cookieSigner{key: testSessionKey}
+	now := time.Now()
+	signed := c.sign(SessionCookie, "octocat", now.Add(time.Hour))
+	if v, err := c.verify(SessionCookie, signed, now); err != nil || v != "octocat" {
+		t.Errorf("expected signed value octocat received %q, %v", v, err)
+	}
+	if _, err := c.verify(SessionCookie, signed, now.Add(2*time.Hour)); err == nil {
+		t.Error("expected expired value to be rejected")
+	}
+	forged := c.sign(SessionCookie, "octocat", now.Add(time.Hour))
+	forged = strings.Replace(forged, forged[:4], "eyJ3", 1)
+	if _, err := c.verify(SessionCookie, forged, now); err == nil {
+		t.Error("expected tampered value to be rejected")
+	}
+	other := &cookieSigner{key: []byte("another key of at least 32 bytes")}
+	if _, err := other.verify(SessionCookie, signed, now); err == nil {
+		t.Error("expected value signed with another key to be rejected")
+	}
+	if _, err := c.verify(LoginStateCookie, signed, now); err == nil {
+		t.Error("expected value signed for another cookie to be rejected")
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	s := newAuthTestServer(&staticAuthenticator{user: "octocat"})
+	session := &http.Cookie{Name: SessionCookie, Value: s.cookies.sign(SessionCookie, "octocat", time.Now().Add(time.Hour))}
+
+	for _, tc := range []struct {
+		description      string
+		url              string
+		cookie           *http.Cookie
+		handler          http.HandlerFunc
+		expectedStatus   int
+		expectedLocation string
+	}{{
+		description:    "serves public namespaces anonymously",
+		url:            "/?namespace=default&buildid=12345",
+		handler:        s.serveLog,
+		expectedStatus: http.StatusOK,
+	}, {
+		description:      "redirects anonymous users of private log pages to sign in",
+		url:              "/?namespace=test-pods&buildid=12345",
+		handler:          s.serveLog,
+		expectedStatus:   http.StatusFound,
+		expectedLocation: "/auth/login?redirect=%2F%3Fnamespace%3Dtest-pods%26buildid%3D12345",
+	}, {
+		description:    "rejects anonymous api requests for private namespaces",
+		url:            "/api/v1/entries?namespace=test-pods&buildid=12345",
+		handler:        s.serveEntriesAPI,
+		expectedStatus: http.StatusUnauthorized,
+	}, {
+		description:    "rejects anonymous streams of private namespaces",
+		url:            "/stream?namespace=test-pods&buildid=12345",
+		handler:        s.streamLog,
+		expectedStatus: http.StatusUnauthorized,
+	}, {
+		description:    "rejects anonymous build listings of private namespaces",
+		url:            "/api/v1/builds?namespace=test-pods",
+		handler:        s.serveBuildsAPI,
+		expectedStatus: http.StatusUnauthorized,
+	}, {
+		description:    "serves private namespaces to signed in users",
+		url:            "/api/v1/entries?namespace=test-pods&buildid=12345",
+		cookie:         session,
+		handler:        s.serveEntriesAPI,
+		expectedStatus: http.StatusOK,
+	}, {
+		description:    "rejects forged sessions",
+		url:            "/api/v1/entries?namespace=test-pods&buildid=12345",
+		cookie:         &http.Cookie{Name: SessionCookie, Value: session.Value + "x"},
+		handler:        s.serveEntriesAPI,
+		expectedStatus: http.StatusUnauthorized,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.cookie != nil {
+				r.AddCookie(tc.cookie)
+			}
+			w := httptest.NewRecorder()
+			tc.handler(w, r)
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tc.expectedLocation {
+				t.Errorf("expected location %q received %q", tc.expectedLocation, loc)
+			}
+		})
+	}
+}
+
+func TestIndexHidesPrivateNamespaces(t *testing.T) {
+	s := newAuthTestServer(&staticAuthenticator{user: "octocat"})
+	serve := func(cookie *http.Cookie) []NamespaceBuilds {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/builds", nil)
+		if cookie != nil {
+			r.AddCookie(cookie)
+		}
+		w := httptest.NewRecorder()
+		s.serveBuildsAPI(w, r)
+		var index []NamespaceBuilds
+		if err := json.NewDecoder(w.Body).Decode(&index); err != nil {
+			t.Fatalf("unexpected error decoding response: %v", err)
+		}
+		return index
+	}
+	if index := serve(nil); len(index) != 1 || index[0].Namespace != "default" {
+		t.Errorf("expected anonymous users to only see public namespaces but received %+v", index)
+	}
+	session := &http.Cookie{Name: SessionCookie, Value: s.cookies.sign(SessionCookie, "octocat", time.Now().Add(time.Hour))}
+	if index := serve(session); len(index) != 2 {
+		t.Errorf("expected signed in users to see every namespace but received %+v", index)
+	}
+}
+
+// signIn runs the login flow of s, returning the callback response.
+func signIn(t *testing.T, s *Server, redirect string, callbackURL func(state string) string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.login(w, httptest.NewRequest(http.MethodGet, "/auth/login?redirect="+url.QueryEscape(redirect), nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("expected login to redirect but received %d", w.Code)
+	}
+	loc, err := url.Parse(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid login redirect: %v", err)
+	}
+	r := httptest.NewRequest(http.MethodGet, callbackURL(loc.Query().Get("state")), nil)
+	for _, c := range w.Result().Cookies() {
+		r.AddCookie(c)
+	}
+	w = httptest.NewRecorder()
+	s.callback(w, r)
+	return w
+}
+
+func TestSwappedLoginCookie(t *testing.T) {
+	s := newAuthTestServer(&staticAuthenticator{user: "octocat"})
+	w := httptest.NewRecorder()
+	s.login(w, httptest.NewRequest(http.MethodGet, "/auth/login?redirect=/", nil))
+	var state *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == LoginStateCookie {
+			state = c
+		}
+	}
+	if state == nil {
+		t.Fatal("expected a login state cookie")
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookie, Value: state.Value})
+	if user, ok := s.sessionUser(r); ok {
+		t.Errorf("expected login state cookie to be rejected as a session received %q", user)
+	}
+}
+
+func TestLoginCallback(t *testing.T) {
+	for _, tc := range []struct {
+		description      string
+		auth             Authenticator
+		redirect         string
+		tamperState      bool
+		expectedStatus   int
+		expectedLocation string
+		expectSession    bool
+	}{{
+		description:      "starts a session and returns to the requested page",
+		auth:             &staticAuthenticator{user: "octocat"},
+		redirect:         "/?namespace=test-pods&buildid=12345",
+		expectedStatus:   http.StatusFound,
+		expectedLocation: "/?namespace=test-pods&buildid=12345",
+		expectSession:    true,
+	}, {
+		description:      "does not redirect to other sites",
+		auth:             &staticAuthenticator{user: "octocat"},
+		redirect:         "//evil.example.com/",
+		expectedStatus:   http.StatusFound,
+		expectedLocation: "/",
+		expectSession:    true,
+	}, {
+		description:    "rejects mismatched state",
+		auth:           &staticAuthenticator{user: "octocat"},
+		redirect:       "/",
+		tamperState:    true,
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		description:    "forbids users the provider does not allow",
+		auth:           &staticAuthenticator{err: errors.New("not a member")},
+		redirect:       "/",
+		expectedStatus: http.StatusForbidden,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			s := newAuthTestServer(tc.auth)
+			w := signIn(t, s, tc.redirect, func(state string) string {
+				if tc.tamperState {
+					state = "forged"
+				}
+				return "/auth/callback?code=abc&state=" + url.QueryEscape(state)
+			})
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+			if loc := w.Header().Get("Location"); loc != tc.expectedLocation {
+				t.Errorf("expected location %q received %q", tc.expectedLocation, loc)
+			}
+			var session *http.Cookie
+			for _, c := range w.Result().Cookies() {
+				if c.Name == SessionCookie {
+					session = c
+				}
+			}
+			if tc.expectSession {
+				if session == nil || !session.HttpOnly || !session.Secure {
+					t.Fatalf("expected a secure session cookie but received %+v", session)
+				}
+				r := httptest.NewRequest(http.MethodGet, "/", nil)
+				r.AddCookie(session)
+				if user, ok := s.sessionUser(r); !ok || user != "octocat" {
+					t.Errorf("expected session of octocat received %q", user)
+				}
+			} else if session != nil {
+				t.Errorf("expected no session but received %+v", session)
+			}
+		})
+	}
+}
+
+func TestGitHubAuthenticator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/login/oauth/access_token":
+			if err := r.ParseForm(); err != nil || r.PostForm.Get("code") == "" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"access_token": "token-" + r.PostForm.Get("code"),
+				"token_type":   "bearer",
+			})
+		case "/user":
+			login := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer token-")
+			_ = json.NewEncoder(w).Encode(map[string]string{"login": login})
+		case "/user/memberships/orgs/tektoncd":
+			if r.Header.Get("Authorization") != "Bearer token-octocat" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]string{"state": "active"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	a := &githubAuthenticator{
+		oauth: &oauth2.Config{
+			ClientID:     "client",
+			ClientSecret: "secret",
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  srv.URL + "/login/oauth/authorize",
+				TokenURL: srv.URL + "/login/oauth/access_token",
+			},
+		},
+		org:    "tektoncd",
+		apiURL: srv.URL,
+	}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, srv.Client())
+	if user, err := a.Authenticate(ctx, "octocat"); err != nil || user != "octocat" {
+		t.Errorf("expected member octocat to sign in but received %q, %v", user, err)
+	}
+	if _, err := a.Authenticate(ctx, "mallory"); err == nil {
+		t.Error("expected non-member to be rejected")
+	}
+}
+
+func TestCheckEmailDomain(t *testing.T) {
+	for _, tc := range []struct {
+		email     string
+		verified  bool
+		domain    string
+		shouldErr bool
+	}{{
+		email: "someone@gmail.com",
+	}, {
+		email:    "dev@tekton.dev",
+		verified: true,
+		domain:   "tekton.dev",
+	}, {
+		email:     "dev@tekton.dev",
+		domain:    "tekton.dev",
+		shouldErr: true,
+	}, {
+		email:     "dev@nottekton.dev",
+		verified:  true,
+		domain:    "tekton.dev",
+		shouldErr: true,
+	}} {
+		err := checkEmailDomain(tc.email, tc.verified, tc.domain)
+		if (err != nil) != tc.shouldErr {
+			t.Errorf("unexpected result for %s in %q: %v", tc.email, tc.domain, err)
+		}
+	}
+}
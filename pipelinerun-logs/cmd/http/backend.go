@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/logging"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+// Backend is a source of log entries for a PipelineRun.
+type Backend interface {
+	// Entries returns an iterator over the log entries matching the
+	// query, ordered by timestamp.
+	Entries(ctx context.Context, query *Query) EntryIterator
+	// Builds returns an iterator over the log entries of all builds in
+	// the query's namespace, newest first where the backend supports it.
+	Builds(ctx context.Context, query *IndexQuery) EntryIterator
+}
+
+// EntryIterator iterates over log entries returned by a Backend. Next
+// returns iterator.Done once there are no more entries.
+type EntryIterator interface {
+	Next() (*logging.Entry, error)
+}
+
+// NewBackend returns the Backend selected by the -backend flag.
+func NewBackend(ctx context.Context, conf *config.Config) (Backend, error) {
+	switch conf.Backend {
+	case "", config.BackendStackdriver:
+		return NewStackdriverBackend(ctx, conf.Project)
+	case config.BackendLoki:
+		return NewLokiBackend(conf.LokiURL, nil), nil
+	case config.BackendFile:
+		return NewFileBackend(conf.LogDir), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q", conf.Backend)
+}
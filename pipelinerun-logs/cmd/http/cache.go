@@ -0,0 +1,353 @@
+package main
+
+import (
+	"compress/gzip"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+)
+
+var cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "pipelinerun_logs_cache_requests_total",
+	Help: "Number of builds looked up in the log cache, by cache and result (hit or miss).",
+}, []string{"cache", "result"})
+
+func init() {
+	prometheus.MustRegister(cacheRequests)
+}
+
+// CachedBuild holds all log entries of a build, in the same format the
+// file backend reads from disk.
+type CachedBuild struct {
+	Entries []fileEntry `json:"entries"`
+	// Expires is the zero time for builds that have finished, since
+	// their logs no longer change.
+	Expires time.Time `json:"expires,omitempty"`
+	// TooLarge is set instead of Entries for builds with more than
+	// MaxFetchedLogEntries entries, which only grow.
+	TooLarge bool `json:"tooLarge,omitempty"`
+}
+
+// expired reports whether a cached build has to be read again.
+func (b *CachedBuild) expired(now time.Time) bool {
+	return !b.Expires.IsZero() && now.After(b.Expires)
+}
+
+// Cache stores the entries of builds keyed by namespace and build id.
+type Cache interface {
+	// Name identifies the cache in metrics.
+	Name() string
+	Get(key string) (*CachedBuild, bool)
+	Set(key string, build *CachedBuild) error
+}
+
+// NewCache returns the Cache selected by the -cache flag, or nil if
+// caching is disabled.
+func NewCache(conf *config.Config) (Cache, error) {
+	switch conf.Cache {
+	case "":
+		return nil, nil
+	case config.CacheMemory:
+		return NewLRUCache(conf.CacheSize), nil
+	case config.CacheDisk:
+		return NewDiskCache(conf.CacheDir, conf.CacheSize)
+	}
+	return nil, fmt.Errorf("unknown cache %q", conf.Cache)
+}
+
+// LRUCache keeps up to size builds in memory, evicting the least recently
+// used build when full.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	order *list.List
+	items map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	build *CachedBuild
+}
+
+// NewLRUCache returns an LRUCache holding up to size builds.
+func NewLRUCache(size int) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		order: list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (c *LRUCache) Name() string {
+	return config.CacheMemory
+}
+
+func (c *LRUCache) Get(key string) (*CachedBuild, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).build, true
+}
+
+func (c *LRUCache) Set(key string, build *CachedBuild) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).build = build
+		c.order.MoveToFront(el)
+		return nil
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, build: build})
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+// DiskCache stores up to size builds as gzip'd JSON files in a directory,
+// so that cached logs survive restarts. Files are evicted least recently
+// used first. Cached logs are not redacted, so the directory is only
+// accessible to the server's user.
+type DiskCache struct {
+	mu   sync.Mutex
+	dir  string
+	size int
+}
+
+// NewDiskCache returns a DiskCache storing up to size builds under dir,
+// which is created if it does not exist.
+func NewDiskCache(dir string, size int) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, xerrors.Errorf("error creating cache dir: %w", err)
+	}
+	// MkdirAll leaves the mode of existing directories as is.
+	if err := os.Chmod(dir, 0700); err != nil {
+		return nil, xerrors.Errorf("error restricting cache dir: %w", err)
+	}
+	return &DiskCache{dir: dir, size: size}, nil
+}
+
+func (c *DiskCache) Name() string {
+	return config.CacheDisk
+}
+
+// path returns the file a build is stored in. Keys are hashed since they
+// are built from request parameters.
+func (c *DiskCache) path(key string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x.json.gz", sha256.Sum256([]byte(key))))
+}
+
+func (c *DiskCache) Get(key string) (*CachedBuild, bool) {
+	path := c.path(key)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer f.Close()
+	// The modification time orders files for eviction.
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, false
+	}
+	defer gz.Close()
+	var build CachedBuild
+	if err := json.NewDecoder(gz).Decode(&build); err != nil {
+		return nil, false
+	}
+	return &build, true
+}
+
+func (c *DiskCache) Set(key string, build *CachedBuild) error {
+	// Write to a temporary file first so that readers never see a
+	// partially written build.
+	tmp, err := os.CreateTemp(c.dir, "build-*.tmp")
+	if err != nil {
+		return xerrors.Errorf("error creating cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	gz := gzip.NewWriter(tmp)
+	if err := json.NewEncoder(gz).Encode(build); err != nil {
+		tmp.Close()
+		return xerrors.Errorf("error writing cache file: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return xerrors.Errorf("error writing cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return xerrors.Errorf("error writing cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return xerrors.Errorf("error storing cache file: %w", err)
+	}
+	return c.evict()
+}
+
+// evict removes the least recently used builds beyond the cache size.
+func (c *DiskCache) evict() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json.gz"))
+	if err != nil || len(files) <= c.size {
+		return err
+	}
+	type cached struct {
+		path    string
+		modTime time.Time
+	}
+	var builds []cached
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			// removed concurrently
+			continue
+		}
+		builds = append(builds, cached{path: f, modTime: info.ModTime()})
+	}
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].modTime.After(builds[j].modTime)
+	})
+	for i := c.size; i < len(builds); i++ {
+		if err := os.Remove(builds[i].path); err != nil && !os.IsNotExist(err) {
+			return xerrors.Errorf("error evicting cache file: %w", err)
+		}
+	}
+	return nil
+}
+
+// CachingBackend serves build entries from a Cache, reading each build
+// from the wrapped Backend once. Builds that have not logged within the
+// stream idle timeout are considered finished and cached until evicted,
+// builds still in progress are cached for the cache TTL.
+type CachingBackend struct {
+	Backend
+	cache       Cache
+	ttl         time.Duration
+	idleTimeout time.Duration
+	loads       singleflight.Group
+}
+
+// NewCachingBackend wraps backend with cache.
+func NewCachingBackend(backend Backend, cache Cache, conf *config.Config) *CachingBackend {
+	return &CachingBackend{
+		Backend:     backend,
+		cache:       cache,
+		ttl:         conf.CacheTTL,
+		idleTimeout: conf.StreamIdleTimeout,
+	}
+}
+
+// Entries returns the cached entries of the query's build within the time
+// range of the query. As with the file backend the rest of the filter is
+// applied by the server.
+func (b *CachingBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	build, err := b.build(ctx, query)
+	if err != nil {
+		return &sliceIterator{err: err}
+	}
+	if build.TooLarge {
+		return b.Backend.Entries(ctx, query)
+	}
+	start, end := query.Start(), query.Filter.End
+	var entries []*logging.Entry
+	for i := range build.Entries {
+		fe := &build.Entries[i]
+		if fe.Timestamp.Before(start) || (!end.IsZero() && fe.Timestamp.After(end)) {
+			continue
+		}
+		entries = append(entries, fe.toEntry())
+	}
+	return &sliceIterator{entries: entries}
+}
+
+// build returns the cached build of a query, reading it from the wrapped
+// backend on a miss. Concurrent misses for the same build share a single
+// backend query, which is not canceled when the caller that started it
+// goes away.
+func (b *CachingBackend) build(ctx context.Context, query *Query) (*CachedBuild, error) {
+	key := query.Namespace + "/" + query.BuildID
+	if build, ok := b.cache.Get(key); ok && !build.expired(time.Now()) {
+		cacheRequests.WithLabelValues(b.cache.Name(), "hit").Inc()
+		return build, nil
+	}
+	cacheRequests.WithLabelValues(b.cache.Name(), "miss").Inc()
+
+	loadCtx := context.WithoutCancel(ctx)
+	ch := b.loads.DoChan(key, func() (interface{}, error) {
+		build, err := b.load(loadCtx, query)
+		if err != nil {
+			return nil, err
+		}
+		if err := b.cache.Set(key, build); err != nil {
+			return nil, err
+		}
+		return build, nil
+	})
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*CachedBuild), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// load reads all entries of a build from the wrapped backend.
+func (b *CachingBackend) load(ctx context.Context, query *Query) (*CachedBuild, error) {
+	iter := b.Backend.Entries(ctx, &Query{
+		Project:   query.Project,
+		Cluster:   query.Cluster,
+		Namespace: query.Namespace,
+		BuildID:   query.BuildID,
+	})
+	build := &CachedBuild{Entries: make([]fileEntry, 0)}
+	var last time.Time
+	for {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("error iterating log entries: %w", err)
+		}
+		if len(build.Entries) == MaxFetchedLogEntries {
+			return &CachedBuild{TooLarge: true}, nil
+		}
+		fe, err := newFileEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		build.Entries = append(build.Entries, *fe)
+		if entry.Timestamp.After(last) {
+			last = entry.Timestamp
+		}
+	}
+	if now := time.Now(); len(build.Entries) == 0 || now.Sub(last) < b.idleTimeout {
+		build.Expires = now.Add(b.ttl)
+	}
+	return build, nil
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+func TestLRUCache(t *testing.T) {
+	c := NewLRUCache(2)
+	for _, key := range []string{"default/1", "default/2"} {
+		if err := c.Set(key, &CachedBuild{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// touch the first build so that the second one is evicted
+	if _, ok := c.Get("default/1"); !ok {
+		t.Fatal("expected build 1 to be cached")
+	}
+	if err := c.Set("default/3", &CachedBuild{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for key, expected := range map[string]bool{"default/1": true, "default/2": false, "default/3": true} {
+		if _, ok := c.Get(key); ok != expected {
+			t.Errorf("expected build %s cached to be %t", key, expected)
+		}
+	}
+}
+
+func TestDiskCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cache")
+	c, err := NewDiskCache(dir, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0700 {
+		t.Fatalf("expected cache dir to be private but received %v, %v", info.Mode(), err)
+	}
+	if _, ok := c.Get("default/12345"); ok {
+		t.Fatal("expected empty cache")
+	}
+	ts := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	build := &CachedBuild{Entries: []fileEntry{{Timestamp: ts, InsertID: "a", Payload: "hello"}}}
+	if err := c.Set("default/12345", build); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got, ok := c.Get("default/12345")
+	if !ok {
+		t.Fatal("expected build to be cached")
+	}
+	if len(got.Entries) != 1 || !got.Entries[0].Timestamp.Equal(ts) || got.Entries[0].InsertID != "a" || got.Entries[0].Payload != "hello" {
+		t.Errorf("unexpected cached entries %+v", got.Entries)
+	}
+
+	// The least recently used build is evicted beyond the cache size.
+	if err := c.Set("default/2", &CachedBuild{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	old := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(c.path("default/2"), old, old); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("default/3", &CachedBuild{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for key, expected := range map[string]bool{"default/12345": true, "default/2": false, "default/3": true} {
+		if _, ok := c.Get(key); ok != expected {
+			t.Errorf("expected build %s cached to be %t", key, expected)
+		}
+	}
+}
+
+func TestCachingBackend(t *testing.T) {
+	conf := &config.Config{
+		Project:           "FooProject",
+		Namespace:         "default",
+		PageSize:          10000,
+		StreamIdleTimeout: 10 * time.Minute,
+		CacheTTL:          time.Hour,
+	}
+	upstream := &countingBackend{Backend: NewFileBackend("testdata/logs")}
+	cache := NewLRUCache(10)
+	s := NewServer(conf, NewCachingBackend(upstream, cache, conf), "../../templates")
+
+	hits := testutil.ToFloat64(cacheRequests.WithLabelValues(config.CacheMemory, "hit"))
+	misses := testutil.ToFloat64(cacheRequests.WithLabelValues(config.CacheMemory, "miss"))
+
+	for _, url := range []string{
+		"/?namespace=default&buildid=12345",
+		"/?namespace=default&buildid=12345&format=text",
+		"/?namespace=default&buildid=12345&task=unit-tests&format=text",
+	} {
+		w := httptest.NewRecorder()
+		s.serveLog(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d received %d for %s", http.StatusOK, w.Code, url)
+		}
+		if strings.Contains(url, "task=unit-tests") && strings.Contains(w.Body.String(), "Cloning") {
+			t.Errorf("expected cached entries to be filtered but received %s", w.Body.String())
+		}
+	}
+	if upstream.entries != 1 {
+		t.Errorf("expected the build to be read from the backend once but it was read %d times", upstream.entries)
+	}
+	if build, ok := cache.Get("default/12345"); !ok || !build.Expires.IsZero() || len(build.Entries) != 3 {
+		t.Errorf("expected finished build to be cached without expiry but received %+v", build)
+	}
+	if d := testutil.ToFloat64(cacheRequests.WithLabelValues(config.CacheMemory, "hit")) - hits; d != 2 {
+		t.Errorf("expected 2 cache hits but received %v", d)
+	}
+	if d := testutil.ToFloat64(cacheRequests.WithLabelValues(config.CacheMemory, "miss")) - misses; d != 1 {
+		t.Errorf("expected 1 cache miss but received %v", d)
+	}
+
+	// Builds without entries may still be starting up and expire.
+	b := NewCachingBackend(upstream, cache, conf)
+	if _, err := b.Entries(context.Background(), &Query{Namespace: "default", BuildID: "99999"}).Next(); err == nil {
+		t.Error("expected no entries for unknown build")
+	}
+	if build, ok := cache.Get("default/99999"); !ok || build.Expires.IsZero() {
+		t.Errorf("expected build in progress to be cached with a ttl but received %+v", build)
+	}
+}
+
+// sizedBackend returns n entries for every build, once release is closed.
+type sizedBackend struct {
+	Backend
+	n       int
+	release chan struct{}
+	mu      sync.Mutex
+	entries int
+}
+
+func (b *sizedBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	b.mu.Lock()
+	b.entries++
+	b.mu.Unlock()
+	if b.release != nil {
+		<-b.release
+	}
+	entries := make([]*logging.Entry, b.n)
+	for i := range entries {
+		entries[i] = &logging.Entry{Timestamp: time.Unix(int64(i), 0), Payload: "line"}
+	}
+	return &sliceIterator{entries: entries}
+}
+
+func TestCachingBackendTooLarge(t *testing.T) {
+	conf := &config.Config{StreamIdleTimeout: 10 * time.Minute, CacheTTL: time.Hour}
+	upstream := &sizedBackend{n: MaxFetchedLogEntries + 1}
+	cache := NewLRUCache(10)
+	b := NewCachingBackend(upstream, cache, conf)
+	query := &Query{Namespace: "default", BuildID: "12345"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := b.Entries(context.Background(), query).Next(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// read once in full, then once per request
+	if upstream.entries != 3 {
+		t.Errorf("expected the build to be read from the backend 3 times but it was read %d times", upstream.entries)
+	}
+	if build, ok := cache.Get("default/12345"); !ok || !build.TooLarge {
+		t.Errorf("expected build to be cached as too large but received %+v", build)
+	}
+}
+
+func TestCachingBackendCanceledCaller(t *testing.T) {
+	conf := &config.Config{StreamIdleTimeout: 10 * time.Minute, CacheTTL: time.Hour}
+	upstream := &sizedBackend{n: 1, release: make(chan struct{})}
+	b := NewCachingBackend(upstream, NewLRUCache(10), conf)
+	query := &Query{Namespace: "default", BuildID: "12345"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := b.build(ctx, query)
+		first <- err
+	}()
+	// wait for the first caller to start loading the build
+	for {
+		upstream.mu.Lock()
+		started := upstream.entries > 0
+		upstream.mu.Unlock()
+		if started {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan error)
+	go func() {
+		_, err := b.build(context.Background(), query)
+		second <- err
+	}()
+
+	cancel()
+	if err := <-first; err != context.Canceled {
+		t.Errorf("expected the canceled caller to fail with %v but received %v", context.Canceled, err)
+	}
+	close(upstream.release)
+	if err := <-second; err != nil {
+		t.Errorf("expected the waiting caller to receive the build but received %v", err)
+	}
+}
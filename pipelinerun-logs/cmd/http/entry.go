@@ -30,4 +30,11 @@ type RenderableEntry struct {
 	Caller        string `json:"caller"`
 	ContainerName string `json:"container"`
 	TimeStamp     string `json:"ts"`
+	Level         string `json:"level,omitempty"`
+	Stacktrace    string `json:"stacktrace,omitempty"`
+	// Test and TestResult are set for go test -json output.
+	Test       string `json:"test,omitempty"`
+	TestResult string `json:"testResult,omitempty"`
+	// Segments holds the styled runs of Message for ANSI colored output.
+	Segments []TextSegment `json:"segments,omitempty"`
 }
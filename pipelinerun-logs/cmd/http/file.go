@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+// FileBackend reads log entries from a local directory. Entries for a
+// build are read from every file under <dir>/<namespace>/<buildid>/, where
+// each line of a file is a JSON encoded fileEntry.
+type FileBackend struct {
+	dir string
+}
+
+// fileEntry is the on-disk format of a single log entry. It is also the
+// format builds are cached in.
+type fileEntry struct {
+	Timestamp time.Time         `json:"timestamp"`
+	InsertID  string            `json:"insertId,omitempty"`
+	LogName   string            `json:"logName"`
+	Container string            `json:"container"`
+	Labels    map[string]string `json:"labels"`
+	Payload   string            `json:"payload"`
+}
+
+// newFileEntry converts a logging Entry into a fileEntry. Structured
+// payloads are stored JSON encoded, which parsePayload reads back the same
+// way.
+func newFileEntry(entry *logging.Entry) (*fileEntry, error) {
+	payload, isString := entry.Payload.(string)
+	if !isString {
+		b, err := json.Marshal(entry.Payload)
+		if err != nil {
+			return nil, xerrors.Errorf("error encoding entry payload: %w", err)
+		}
+		payload = string(b)
+	}
+	return &fileEntry{
+		Timestamp: entry.Timestamp,
+		InsertID:  entry.InsertID,
+		LogName:   entry.LogName,
+		Container: extractContainerName(entry),
+		Labels:    entry.Labels,
+		Payload:   payload,
+	}, nil
+}
+
+// NewFileBackend returns a FileBackend reading logs from dir.
+func NewFileBackend(dir string) *FileBackend {
+	return &FileBackend{dir: dir}
+}
+
+// Entries reads and sorts all entries stored for the query's build. Only
+// the time range of the query's filter is applied here, the server checks
+// the rest of the filter once entries are structured.
+func (b *FileBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	dir, err := b.buildDir(query.Namespace, query.BuildID)
+	if err != nil {
+		return &sliceIterator{err: err}
+	}
+	entries, err := b.readEntries(dir, query.Start(), query.Filter.End)
+	return &sliceIterator{entries: entries, err: err}
+}
+
+// buildDir returns the directory of a build, which must be a direct child
+// of its namespace's directory.
+func (b *FileBackend) buildDir(namespace, buildID string) (string, error) {
+	nsDir := filepath.Clean(filepath.Join(b.dir, namespace))
+	dir := filepath.Join(nsDir, buildID)
+	if filepath.Dir(dir) != nsDir || dir == nsDir {
+		return "", xerrors.Errorf("invalid build id: %q", buildID)
+	}
+	return dir, nil
+}
+
+// Builds reads the entries of every build directory in the query's
+// namespace. Entries are labelled with the build id taken from their
+// directory name.
+func (b *FileBackend) Builds(ctx context.Context, query *IndexQuery) EntryIterator {
+	nsDir := filepath.Join(b.dir, query.Namespace)
+	dirs, err := os.ReadDir(nsDir)
+	if os.IsNotExist(err) {
+		return &sliceIterator{}
+	}
+	if err != nil {
+		return &sliceIterator{err: xerrors.Errorf("error listing builds: %w", err)}
+	}
+	var entries []*logging.Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		buildEntries, err := b.readEntries(filepath.Join(nsDir, d.Name()), query.Since, time.Time{})
+		if err != nil {
+			return &sliceIterator{err: err}
+		}
+		for _, entry := range buildEntries {
+			entry.Labels[StackdriverBuildIDLabel] = d.Name()
+		}
+		entries = append(entries, buildEntries...)
+	}
+	return &sliceIterator{entries: entries}
+}
+
+func (b *FileBackend) readEntries(buildDir string, start, end time.Time) ([]*logging.Entry, error) {
+	var entries []*logging.Entry
+	err := filepath.Walk(buildDir, func(path string, info os.FileInfo, err error) error {
+		if os.IsNotExist(err) {
+			// no logs for this build yet
+			return filepath.SkipDir
+		}
+		if err != nil || info.IsDir() {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rel, err := filepath.Rel(buildDir, path)
+		if err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			if len(scanner.Bytes()) == 0 {
+				continue
+			}
+			var fe fileEntry
+			if err := json.Unmarshal(scanner.Bytes(), &fe); err != nil {
+				return xerrors.Errorf("error parsing %s: %w", path, err)
+			}
+			if fe.Timestamp.Before(start) || (!end.IsZero() && fe.Timestamp.After(end)) {
+				continue
+			}
+			entry := fe.toEntry()
+			entry.InsertID = fmt.Sprintf("%s:%d", rel, line)
+			entries = append(entries, entry)
+		}
+		return scanner.Err()
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("error reading log files: %w", err)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, nil
+}
+
+func (fe *fileEntry) toEntry() *logging.Entry {
+	labels := fe.Labels
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	return &logging.Entry{
+		Timestamp: fe.Timestamp,
+		InsertID:  fe.InsertID,
+		Payload:   fe.Payload,
+		LogName:   fe.LogName,
+		Labels:    labels,
+		Resource: &mrpb.MonitoredResource{
+			Type: StackdriverContainerResourceType,
+			Labels: map[string]string{
+				StackdriverContainerNameLabel: fe.Container,
+			},
+		},
+	}
+}
+
+// sliceIterator is an EntryIterator over entries already held in memory.
+type sliceIterator struct {
+	entries []*logging.Entry
+	err     error
+}
+
+func (it *sliceIterator) Next() (*logging.Entry, error) {
+	if it.err != nil {
+		return nil, it.err
+	}
+	if len(it.entries) == 0 {
+		return nil, iterator.Done
+	}
+	entry := it.entries[0]
+	it.entries = it.entries[1:]
+	return entry, nil
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+)
+
+// FilterParams are the query parameters that narrow down the entries
+// served for a build.
+var FilterParams = []string{"task", "container", "level", "q", "start", "end"}
+
+// levelSeverity orders the zap log levels. Filtering by a level matches
+// entries at that level or above.
+var levelSeverity = map[string]int{
+	"debug":  0,
+	"info":   1,
+	"warn":   2,
+	"error":  3,
+	"dpanic": 4,
+	"panic":  5,
+	"fatal":  6,
+}
+
+// Filter narrows down the entries returned for a query. Backends push as
+// much of the filter as they can into their own query language and the
+// server applies Matches to whatever comes back.
+type Filter struct {
+	Task      string
+	Container string
+	Level     string
+	Search    *regexp.Regexp
+	Start     time.Time
+	End       time.Time
+}
+
+// parseFilter reads a Filter from the query parameters of a url. Times
+// are expected in RFC3339 format.
+func parseFilter(u *url.URL) (Filter, error) {
+	v := u.Query()
+	f := Filter{
+		Task:      v.Get("task"),
+		Container: v.Get("container"),
+		Level:     strings.ToLower(v.Get("level")),
+	}
+	if q := v.Get("q"); q != "" {
+		re, err := regexp.Compile(q)
+		if err != nil {
+			return f, xerrors.Errorf("invalid search expression: %w", err)
+		}
+		f.Search = re
+	}
+	for param, t := range map[string]*time.Time{"start": &f.Start, "end": &f.End} {
+		if s := v.Get(param); s != "" {
+			parsed, err := time.Parse(time.RFC3339, s)
+			if err != nil {
+				return f, xerrors.Errorf("invalid %s time: %w", param, err)
+			}
+			*t = parsed
+		}
+	}
+	if !f.Start.IsZero() && !f.End.IsZero() && f.End.Before(f.Start) {
+		return f, xerrors.New("invalid time range: end is before start")
+	}
+	return f, nil
+}
+
+// Levels returns the levels matched by the filter's level, or nil if the
+// filter does not restrict levels. Unknown levels only match themselves.
+func (f *Filter) Levels() []string {
+	if f.Level == "" {
+		return nil
+	}
+	min, ok := levelSeverity[f.Level]
+	if !ok {
+		return []string{f.Level}
+	}
+	var levels []string
+	for l, severity := range levelSeverity {
+		if severity >= min {
+			levels = append(levels, l)
+		}
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		return levelSeverity[levels[i]] < levelSeverity[levels[j]]
+	})
+	return levels
+}
+
+// Matches reports whether a structured entry passes the filter.
+func (f *Filter) Matches(entry *logging.Entry, re *RenderableEntry) bool {
+	if f.Task != "" && re.TaskName != f.Task {
+		return false
+	}
+	if f.Container != "" && re.ContainerName != f.Container {
+		return false
+	}
+	if levels := f.Levels(); levels != nil {
+		var found bool
+		for _, l := range levels {
+			if strings.EqualFold(re.Level, l) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Search != nil && !f.Search.MatchString(re.Message) {
+		return false
+	}
+	if !f.Start.IsZero() && entry.Timestamp.Before(f.Start) {
+		return false
+	}
+	if !f.End.IsZero() && entry.Timestamp.After(f.End) {
+		return false
+	}
+	return true
+}
+
+// filterValues returns the raw filter parameters of a request for
+// populating the filter form.
+func filterValues(u *url.URL) map[string]string {
+	values := make(map[string]string)
+	for _, p := range FilterParams {
+		values[p] = u.Query().Get(p)
+	}
+	return values
+}
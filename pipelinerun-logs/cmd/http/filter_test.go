@@ -0,0 +1,130 @@
+package main
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestParseFilter(t *testing.T) {
+	for _, tc := range []struct {
+		description   string
+		query         string
+		expected      Filter
+		expectedError string
+	}{{
+		description: "parses every filter parameter",
+		query:       "task=unit-tests&container=step-test&level=WARN&start=2020-01-02T15:04:00Z&end=2020-01-02T15:05:00Z",
+		expected: Filter{
+			Task:      "unit-tests",
+			Container: "step-test",
+			Level:     "warn",
+			Start:     time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC),
+			End:       time.Date(2020, 1, 2, 15, 5, 0, 0, time.UTC),
+		},
+	}, {
+		description:   "rejects invalid search expressions",
+		query:         "q=(",
+		expectedError: "invalid search expression",
+	}, {
+		description:   "rejects invalid times",
+		query:         "start=yesterday",
+		expectedError: "invalid start time",
+	}, {
+		description:   "rejects ranges that end before they start",
+		query:         "start=2020-01-02T15:05:00Z&end=2020-01-02T15:04:00Z",
+		expectedError: "end is before start",
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			f, err := parseFilter(&url.URL{RawQuery: tc.query})
+			if tc.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
+					t.Fatalf("expected error containing %q received %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(f, tc.expected) {
+				t.Errorf("expected filter %+v received %+v", tc.expected, f)
+			}
+		})
+	}
+}
+
+func TestFilterLevels(t *testing.T) {
+	for _, tc := range []struct {
+		level    string
+		expected []string
+	}{{
+		level:    "",
+		expected: nil,
+	}, {
+		level:    "error",
+		expected: []string{"error", "dpanic", "panic", "fatal"},
+	}, {
+		level:    "trace",
+		expected: []string{"trace"},
+	}} {
+		f := Filter{Level: tc.level}
+		if levels := f.Levels(); !reflect.DeepEqual(levels, tc.expected) {
+			t.Errorf("expected levels %v for %q received %v", tc.expected, tc.level, levels)
+		}
+	}
+}
+
+func TestFilterMatches(t *testing.T) {
+	ts := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	entry := &logging.Entry{Timestamp: ts}
+	re := &RenderableEntry{
+		TaskName:      "unit-tests",
+		ContainerName: "step-test",
+		Level:         "warn",
+		Message:       "retrying connection",
+	}
+	for _, tc := range []struct {
+		description string
+		query       string
+		expected    bool
+	}{{
+		description: "matches with an empty filter",
+		expected:    true,
+	}, {
+		description: "matches every criterion",
+		query:       "task=unit-tests&container=step-test&level=info&q=retry&start=2020-01-02T15:04:00Z&end=2020-01-02T15:05:00Z",
+		expected:    true,
+	}, {
+		description: "excludes other tasks",
+		query:       "task=git-clone",
+	}, {
+		description: "excludes other containers",
+		query:       "container=step-clone",
+	}, {
+		description: "excludes lower levels",
+		query:       "level=error",
+	}, {
+		description: "excludes messages not matching the search",
+		query:       "q=^connection",
+	}, {
+		description: "excludes entries before the start",
+		query:       "start=2020-01-02T15:05:00Z",
+	}, {
+		description: "excludes entries after the end",
+		query:       "end=2020-01-02T15:04:00Z",
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			f, err := parseFilter(&url.URL{RawQuery: tc.query})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if matches := f.Matches(entry, re); matches != tc.expected {
+				t.Errorf("expected match %t received %t", tc.expected, matches)
+			}
+		})
+	}
+}
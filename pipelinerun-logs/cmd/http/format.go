@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	FormatHTML   = "html"
+	FormatText   = "text"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+)
+
+// formatContentTypes maps each output format to the content type it is
+// served with. The same content types are accepted in the Accept header.
+var formatContentTypes = map[string]string{
+	FormatHTML:   "text/html; charset=utf-8",
+	FormatText:   "text/plain; charset=utf-8",
+	FormatJSON:   "application/json",
+	FormatNDJSON: "application/x-ndjson",
+}
+
+// formatExtensions are the file extensions used for downloads.
+var formatExtensions = map[string]string{
+	FormatHTML:   "html",
+	FormatText:   "log",
+	FormatJSON:   "json",
+	FormatNDJSON: "ndjson",
+}
+
+// negotiateFormat picks the output format for a request. An explicit
+// format query parameter wins over the Accept header, and html is served
+// when neither asks for anything else.
+func negotiateFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		if _, ok := formatContentTypes[f]; !ok {
+			return "", fmt.Errorf("unsupported format: %q", f)
+		}
+		return f, nil
+	}
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		for f, ct := range formatContentTypes {
+			if ctMediaType, _, _ := mime.ParseMediaType(ct); ctMediaType == mediaType {
+				return f, nil
+			}
+		}
+	}
+	return FormatHTML, nil
+}
+
+// isDownload reports whether the request asked for the logs as a gzip'd
+// attachment instead of inline content.
+func isDownload(r *http.Request) bool {
+	download, _ := strconv.ParseBool(r.URL.Query().Get("download"))
+	return download
+}
+
+// writeEntries writes the template context in the requested format. When
+// download is set the output is gzip'd and served as an attachment.
+func (s *Server) writeEntries(w http.ResponseWriter, format string, download bool, tc *EntriesTemplateContext) error {
+	var out io.Writer = w
+	if download {
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="build-%s.%s.gz"`, tc.BuildID, formatExtensions[format]))
+		gz := gzip.NewWriter(w)
+		defer gz.Close()
+		out = gz
+	} else {
+		w.Header().Set("Content-Type", formatContentTypes[format])
+	}
+
+	switch format {
+	case FormatText:
+		return writeText(out, tc.LogsJSON)
+	case FormatJSON:
+		return json.NewEncoder(out).Encode(tc.LogsJSON)
+	case FormatNDJSON:
+		return writeNDJSON(out, tc.LogsJSON)
+	}
+	return s.entriesTmpl.Execute(out, tc)
+}
+
+// writeText writes entries as plain text, grouped by task and container in
+// the order each group first logged. Entries keep their relative order
+// within a group.
+func writeText(w io.Writer, entries []RenderableEntry) error {
+	type group struct {
+		task, container string
+	}
+	var order []group
+	groups := make(map[group][]RenderableEntry)
+	for _, e := range entries {
+		g := group{task: e.TaskName, container: e.ContainerName}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], e)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, g := range order {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "==> task: %s, container: %s <==\n", g.task, g.container)
+		for _, e := range groups[g] {
+			fmt.Fprintf(bw, "[%s] ", e.TimeStamp)
+			if e.Level != "" {
+				fmt.Fprintf(bw, "%s ", strings.ToUpper(e.Level))
+			}
+			if e.Caller != "" {
+				fmt.Fprintf(bw, "(%s) ", e.Caller)
+			}
+			fmt.Fprintln(bw, e.Message)
+			if e.Stacktrace != "" {
+				fmt.Fprintln(bw, e.Stacktrace)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// writeNDJSON writes one JSON encoded entry per line, flushing to the
+// client as it goes so large logs can be consumed incrementally.
+func writeNDJSON(w io.Writer, entries []RenderableEntry) error {
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for i, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+		if flusher != nil && i%1000 == 999 {
+			flusher.Flush()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	for _, tc := range []struct {
+		description    string
+		url            string
+		accept         string
+		expectedFormat string
+		expectError    bool
+	}{{
+		description:    "defaults to html",
+		url:            "/?buildid=1",
+		expectedFormat: FormatHTML,
+	}, {
+		description:    "defaults to html for browsers",
+		url:            "/?buildid=1",
+		accept:         "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+		expectedFormat: FormatHTML,
+	}, {
+		description:    "reads the accept header",
+		url:            "/?buildid=1",
+		accept:         "application/x-ndjson",
+		expectedFormat: FormatNDJSON,
+	}, {
+		description:    "picks the first supported accept header",
+		url:            "/?buildid=1",
+		accept:         "application/xml, text/plain;q=0.9, application/json;q=0.5",
+		expectedFormat: FormatText,
+	}, {
+		description:    "format param wins over accept header",
+		url:            "/?buildid=1&format=json",
+		accept:         "text/plain",
+		expectedFormat: FormatJSON,
+	}, {
+		description: "errors on unsupported format param",
+		url:         "/?buildid=1&format=xml",
+		expectError: true,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.accept != "" {
+				r.Header.Set("Accept", tc.accept)
+			}
+			f, err := negotiateFormat(r)
+			if err != nil && !tc.expectError {
+				t.Errorf("did not expect error but received %v", err)
+			} else if err == nil && tc.expectError {
+				t.Errorf("expected error but received format %q", f)
+			} else if f != tc.expectedFormat {
+				t.Errorf("expected format %q received %q", tc.expectedFormat, f)
+			}
+		})
+	}
+}
+
+func TestServeLogFormats(t *testing.T) {
+	conf := &config.Config{Project: "FooProject", Namespace: "default", PageSize: 10000}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	serve := func(url string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.serveLog(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d received %d", http.StatusOK, w.Code)
+		}
+		return w
+	}
+
+	t.Run("text", func(t *testing.T) {
+		w := serve("/?namespace=default&buildid=12345&format=text")
+		expected := `==> task: git-clone, container: step-clone <==
+[2020-01-02T15:04:00Z] Cloning into 'plumbing'...
+
+==> task: unit-tests, container: step-unit-test <==
+[2020-01-02T15:04:05Z] ok  	github.com/tektoncd/plumbing/pipelinerun-logs/cmd/http	0.008s
+[2020-01-02T15:04:07Z] ERROR (main.go:42) unit tests finished
+main.main
+	/src/main.go:42
+`
+		if w.Body.String() != expected {
+			t.Errorf("expected text body:\n%s\nreceived:\n%s", expected, w.Body.String())
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+			t.Errorf("unexpected content type %q", ct)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		w := serve("/?namespace=default&buildid=12345&format=json")
+		var entries []RenderableEntry
+		if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+			t.Fatalf("error decoding json body: %v", err)
+		}
+		if len(entries) != 3 || entries[2].Message != "unit tests finished" {
+			t.Errorf("unexpected entries %+v", entries)
+		}
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		w := serve("/?namespace=default&buildid=12345&format=ndjson")
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 lines received %d", len(lines))
+		}
+		var e RenderableEntry
+		if err := json.Unmarshal([]byte(lines[0]), &e); err != nil || e.TaskName != "git-clone" {
+			t.Errorf("unexpected first line %q: %v", lines[0], err)
+		}
+	})
+
+	t.Run("gzip download", func(t *testing.T) {
+		w := serve("/?namespace=default&buildid=12345&format=text&download=true")
+		if cd := w.Header().Get("Content-Disposition"); cd != `attachment; filename="build-12345.log.gz"` {
+			t.Errorf("unexpected content disposition %q", cd)
+		}
+		gz, err := gzip.NewReader(w.Body)
+		if err != nil {
+			t.Fatalf("error reading gzip body: %v", err)
+		}
+		b, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatalf("error reading gzip body: %v", err)
+		}
+		if !strings.Contains(string(b), "unit tests finished") {
+			t.Errorf("expected download to contain log entries but received %q", b)
+		}
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.serveLog(w, httptest.NewRequest(http.MethodGet, "/?namespace=default&buildid=12345&format=xml", nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d received %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
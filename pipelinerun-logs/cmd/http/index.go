@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// MaxIndexEntries caps the number of entries scanned when building
+	// the index of a namespace.
+	MaxIndexEntries = 50000
+
+	BuildStatusRunning  = "running"
+	BuildStatusFailed   = "failed"
+	BuildStatusFinished = "finished"
+)
+
+// BuildSummary describes a build listed in the index.
+type BuildSummary struct {
+	BuildID      string    `json:"buildId"`
+	Namespace    string    `json:"namespace"`
+	PipelineName string    `json:"pipeline,omitempty"`
+	PullRequest  string    `json:"pullRequest,omitempty"`
+	Start        time.Time `json:"start"`
+	End          time.Time `json:"end"`
+	Status       string    `json:"status"`
+	URL          string    `json:"url"`
+}
+
+// IndexTemplateContext is rendered by the index template.
+type IndexTemplateContext struct {
+	Namespaces []NamespaceBuilds
+}
+
+// NamespaceBuilds are the recent builds of a single namespace.
+type NamespaceBuilds struct {
+	Namespace string         `json:"namespace"`
+	Builds    []BuildSummary `json:"builds"`
+}
+
+// buildIndex caches the aggregated builds of each namespace for a while,
+// since computing them scans every entry logged in the lookback window.
+type buildIndex struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]*cachedBuilds
+}
+
+type cachedBuilds struct {
+	builds  []BuildSummary
+	expires time.Time
+}
+
+func newBuildIndex(ttl time.Duration) *buildIndex {
+	return &buildIndex{
+		ttl:     ttl,
+		entries: make(map[string]*cachedBuilds),
+	}
+}
+
+// get returns the cached builds of a namespace, if they have not expired.
+func (i *buildIndex) get(namespace string, now time.Time) ([]BuildSummary, bool) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	c, ok := i.entries[namespace]
+	if !ok || now.After(c.expires) {
+		return nil, false
+	}
+	return c.builds, true
+}
+
+func (i *buildIndex) put(namespace string, builds []BuildSummary, now time.Time) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.entries[namespace] = &cachedBuilds{builds: builds, expires: now.Add(i.ttl)}
+}
+
+// recentBuilds returns the builds of a namespace that logged within the
+// index lookback window, most recently started first.
+func (s *Server) recentBuilds(ctx context.Context, namespace string) ([]BuildSummary, error) {
+	now := time.Now()
+	if builds, ok := s.index.get(namespace, now); ok {
+		return builds, nil
+	}
+	query := &IndexQuery{
+		Project:   s.conf.Project,
+		Cluster:   s.conf.Cluster,
+		Namespace: namespace,
+		Since:     now.Add(-s.conf.IndexLookback),
+	}
+	builds, err := s.aggregateBuilds(namespace, s.backend.Builds(ctx, query), now)
+	if err != nil {
+		return nil, err
+	}
+	s.index.put(namespace, builds, now)
+	return builds, nil
+}
+
+// aggregateBuilds groups entries by build. A build is running if it logged
+// within the stream idle timeout, and failed if it logged at error level
+// or above. At most MaxIndexEntries entries are read from the iterator.
+func (s *Server) aggregateBuilds(namespace string, iter EntryIterator, now time.Time) ([]BuildSummary, error) {
+	builds := make(map[string]*BuildSummary)
+	failed := make(map[string]bool)
+	for count := 0; ; count++ {
+		if count == MaxIndexEntries {
+			truncations.WithLabelValues(TruncationIndex).Inc()
+			break
+		}
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("error iterating log entries: %w", err)
+		}
+		buildID := entry.Labels[StackdriverBuildIDLabel]
+		if buildID == "" {
+			continue
+		}
+		b, ok := builds[buildID]
+		if !ok {
+			b = &BuildSummary{
+				BuildID:   buildID,
+				Namespace: namespace,
+				Start:     entry.Timestamp,
+				End:       entry.Timestamp,
+				URL:       buildURL(namespace, buildID),
+			}
+			builds[buildID] = b
+		}
+		if b.PipelineName == "" {
+			b.PipelineName = entry.Labels[TektonPipelineNameLabel]
+		}
+		if b.PullRequest == "" {
+			b.PullRequest = entry.Labels[StackdriverPullLabel]
+		}
+		if entry.Timestamp.Before(b.Start) {
+			b.Start = entry.Timestamp
+		}
+		if entry.Timestamp.After(b.End) {
+			b.End = entry.Timestamp
+		}
+		if !failed[buildID] && isErrorEntry(entry) {
+			failed[buildID] = true
+		}
+	}
+
+	summaries := make([]BuildSummary, 0, len(builds))
+	for id, b := range builds {
+		switch {
+		case now.Sub(b.End) < s.conf.StreamIdleTimeout:
+			b.Status = BuildStatusRunning
+		case failed[id]:
+			b.Status = BuildStatusFailed
+		default:
+			b.Status = BuildStatusFinished
+		}
+		summaries = append(summaries, *b)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Start.After(summaries[j].Start)
+	})
+	return summaries, nil
+}
+
+// isErrorEntry reports whether an entry was logged at error level or
+// above. Entries that cannot be parsed are not considered errors.
+func isErrorEntry(entry *logging.Entry) bool {
+	re, err := parsePayload(entry)
+	if err != nil {
+		return false
+	}
+	severity, ok := levelSeverity[re.Level]
+	return ok && severity >= levelSeverity["error"]
+}
+
+// buildURL returns the url of a build's log page.
+func buildURL(namespace, buildID string) string {
+	v := url.Values{}
+	v.Set("namespace", namespace)
+	v.Set("buildid", buildID)
+	return "/?" + v.Encode()
+}
+
+// indexNamespaces returns the namespaces listed by an index request,
+// either the one asked for or all supported namespaces the user may read.
+// If the request cannot be served an error status is written and ok is
+// false.
+func (s *Server) indexNamespaces(w http.ResponseWriter, r *http.Request) (namespaces []string, ok bool) {
+	if ns := r.URL.Query().Get("namespace"); ns != "" {
+		if _, ok := s.namespaces[ns]; !ok {
+			log.Printf("disallowing request for builds: unsupported namespace: %q", ns)
+			w.WriteHeader(http.StatusNotFound)
+			return nil, false
+		}
+		if !s.authorize(w, r, ns) {
+			return nil, false
+		}
+		return []string{ns}, true
+	}
+	for ns := range s.namespaces {
+		if s.canRead(r, ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	return namespaces, true
+}
+
+// fetchIndex collects the recent builds of the namespaces of a request.
+func (s *Server) fetchIndex(w http.ResponseWriter, r *http.Request) ([]NamespaceBuilds, bool) {
+	namespaces, ok := s.indexNamespaces(w, r)
+	if !ok {
+		return nil, false
+	}
+	index := make([]NamespaceBuilds, 0, len(namespaces))
+	for _, ns := range namespaces {
+		builds, err := s.recentBuilds(r.Context(), ns)
+		if err != nil {
+			log.Printf("error listing builds in %s: %v", ns, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return nil, false
+		}
+		index = append(index, NamespaceBuilds{Namespace: ns, Builds: builds})
+	}
+	return index, true
+}
+
+// serveIndex serves an html page listing the recent builds of every
+// supported namespace, or of the namespace given in the request.
+func (s *Server) serveIndex(w http.ResponseWriter, r *http.Request) {
+	index, ok := s.fetchIndex(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", formatContentTypes[FormatHTML])
+	if err := s.indexTmpl.Execute(w, &IndexTemplateContext{Namespaces: index}); err != nil {
+		log.Printf("error writing index: %v", err)
+		errorsTotal.WithLabelValues(ErrorWrite).Inc()
+	}
+}
+
+// serveBuildsAPI serves the recent builds as a JSON document.
+func (s *Server) serveBuildsAPI(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s?%s", r.URL.Path, r.URL.RawQuery)
+
+	index, ok := s.fetchIndex(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", formatContentTypes[FormatJSON])
+	if err := json.NewEncoder(w).Encode(index); err != nil {
+		log.Printf("error writing builds: %v", err)
+		errorsTotal.WithLabelValues(ErrorWrite).Inc()
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+func newIndexTestServer(backend Backend) *Server {
+	conf := &config.Config{
+		Project:           "FooProject",
+		Namespace:         "default,test-pods",
+		PageSize:          10000,
+		StreamIdleTimeout: 10 * time.Minute,
+		IndexLookback:     100 * 365 * 24 * time.Hour,
+		IndexCacheTTL:     time.Hour,
+	}
+	return NewServer(conf, backend, "../../templates")
+}
+
+func TestAggregateBuilds(t *testing.T) {
+	s := newIndexTestServer(NewFileBackend("testdata/logs"))
+	builds, err := s.recentBuilds(context.Background(), "default")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []BuildSummary{{
+		BuildID:   "67890",
+		Namespace: "default",
+		Start:     time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		End:       time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		Status:    BuildStatusFinished,
+		URL:       "/?buildid=67890&namespace=default",
+	}, {
+		BuildID:      "12345",
+		Namespace:    "default",
+		PipelineName: "plumbing-ci",
+		PullRequest:  "42",
+		Start:        time.Date(2020, 1, 2, 15, 4, 0, 0, time.UTC),
+		End:          time.Date(2020, 1, 2, 15, 4, 7, 0, time.UTC),
+		Status:       BuildStatusFailed,
+		URL:          "/?buildid=12345&namespace=default",
+	}}
+	if len(builds) != len(expected) {
+		t.Fatalf("expected %d builds received %+v", len(expected), builds)
+	}
+	for i := range expected {
+		if !builds[i].Start.Equal(expected[i].Start) || !builds[i].End.Equal(expected[i].End) {
+			t.Errorf("expected build %s to run from %s to %s received %s to %s", expected[i].BuildID, expected[i].Start, expected[i].End, builds[i].Start, builds[i].End)
+		}
+		builds[i].Start, builds[i].End = expected[i].Start, expected[i].End
+		if builds[i] != expected[i] {
+			t.Errorf("expected build %+v received %+v", expected[i], builds[i])
+		}
+	}
+}
+
+func TestAggregateBuildsRunning(t *testing.T) {
+	s := newIndexTestServer(NewFileBackend("testdata/logs"))
+	now := time.Date(2020, 1, 2, 15, 5, 0, 0, time.UTC)
+	builds, err := s.aggregateBuilds("default", s.backend.Builds(context.Background(), &IndexQuery{Namespace: "default"}), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, b := range builds {
+		if b.Status != BuildStatusRunning {
+			t.Errorf("expected build %s logging within the idle timeout to be running but was %s", b.BuildID, b.Status)
+		}
+	}
+}
+
+// countingBackend counts the queries it receives.
+type countingBackend struct {
+	Backend
+	entries int
+	builds  int
+}
+
+func (b *countingBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	b.entries++
+	return b.Backend.Entries(ctx, query)
+}
+
+func (b *countingBackend) Builds(ctx context.Context, query *IndexQuery) EntryIterator {
+	b.builds++
+	return b.Backend.Builds(ctx, query)
+}
+
+func TestRecentBuildsCached(t *testing.T) {
+	backend := &countingBackend{Backend: NewFileBackend("testdata/logs")}
+	s := newIndexTestServer(backend)
+	for i := 0; i < 3; i++ {
+		if _, err := s.recentBuilds(context.Background(), "default"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if backend.builds != 1 {
+		t.Errorf("expected a single index query to be cached but received %d", backend.builds)
+	}
+}
+
+func TestServeIndex(t *testing.T) {
+	s := newIndexTestServer(NewFileBackend("testdata/logs"))
+
+	for _, tc := range []struct {
+		description    string
+		url            string
+		expectedStatus int
+		expectedBody   []string
+	}{{
+		description:    "lists recent builds of every namespace",
+		url:            "/",
+		expectedStatus: http.StatusOK,
+		expectedBody: []string{
+			`Namespace "default"`,
+			`<a href="/?buildid=67890&amp;namespace=default">67890</a>`,
+			`<a href="/?buildid=12345&amp;namespace=default">12345</a>`,
+			`#42`,
+			`<td class="status-failed">failed</td>`,
+			`Namespace "test-pods"`,
+			`No recent builds.`,
+		},
+	}, {
+		description:    "lists recent builds of the requested namespace",
+		url:            "/?namespace=test-pods",
+		expectedStatus: http.StatusOK,
+		expectedBody:   []string{`Namespace "test-pods"`, `No recent builds.`},
+	}, {
+		description:    "disallows unsupported namespaces",
+		url:            "/?namespace=kube-system",
+		expectedStatus: http.StatusNotFound,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.serveLog(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+			body := w.Body.String()
+			last := -1
+			for _, expected := range tc.expectedBody {
+				i := strings.Index(body, expected)
+				if i < 0 {
+					t.Fatalf("expected body to contain %s", expected)
+				}
+				if i < last {
+					t.Errorf("expected %s to be rendered after %s", expected, tc.expectedBody[0])
+				}
+				last = i
+			}
+		})
+	}
+}
+
+func TestServeBuildsAPI(t *testing.T) {
+	s := newIndexTestServer(NewFileBackend("testdata/logs"))
+	w := httptest.NewRecorder()
+	s.serveBuildsAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/builds?namespace=default", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d received %d", http.StatusOK, w.Code)
+	}
+	var index []NamespaceBuilds
+	if err := json.NewDecoder(w.Body).Decode(&index); err != nil {
+		t.Fatalf("unexpected error decoding response: %v", err)
+	}
+	if len(index) != 1 || index[0].Namespace != "default" || len(index[0].Builds) != 2 {
+		t.Fatalf("unexpected builds %+v", index)
+	}
+	if b := index[0].Builds[1]; b.BuildID != "12345" || b.PipelineName != "plumbing-ci" || b.PullRequest != "42" {
+		t.Errorf("unexpected build %+v", b)
+	}
+}
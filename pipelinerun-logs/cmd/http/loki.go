@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+	mrpb "google.golang.org/genproto/googleapis/api/monitoredres"
+)
+
+const (
+	// LokiBatchSize is the number of entries requested from Loki at once.
+	LokiBatchSize = 5000
+	// LokiLookback is how far back in time queries against Loki reach.
+	LokiLookback = 30 * 24 * time.Hour
+
+	LokiContainerLabel    = "container"
+	LokiPipelineNameLabel = "tekton_dev_pipeline"
+	LokiStreamLabel       = "stream"
+	LokiTaskNameLabel     = "tekton_dev_task"
+)
+
+// LokiBackend reads log entries from Grafana Loki over its HTTP API.
+type LokiBackend struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewLokiBackend returns a LokiBackend that queries the Loki instance at
+// baseURL. If client is nil http.DefaultClient is used.
+func NewLokiBackend(baseURL string, client *http.Client) *LokiBackend {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &LokiBackend{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  client,
+	}
+}
+
+// Entries runs the query's LogQL selector against Loki.
+func (b *LokiBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	start := time.Now().Add(-LokiLookback)
+	if query.Start().After(start) {
+		start = query.Start()
+	}
+	return &lokiIterator{
+		ctx:     ctx,
+		backend: b,
+		logQL:   query.ToLogQL(),
+		start:   start,
+		end:     query.Filter.End,
+	}
+}
+
+// Builds runs the index query's LogQL selector against Loki, newest
+// entries first.
+func (b *LokiBackend) Builds(ctx context.Context, query *IndexQuery) EntryIterator {
+	return &lokiIterator{
+		ctx:      ctx,
+		backend:  b,
+		logQL:    query.ToLogQL(),
+		start:    query.Since,
+		end:      time.Now(),
+		backward: true,
+	}
+}
+
+// lokiQueryResponse is the subset of the query_range response body that
+// is needed to build log entries.
+type lokiQueryResponse struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Stream map[string]string `json:"stream"`
+			Values [][2]string       `json:"values"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// lokiIterator pages through a LogQL query in ascending timestamp order,
+// or descending order if backward is set.
+type lokiIterator struct {
+	ctx      context.Context
+	backend  *LokiBackend
+	logQL    string
+	start    time.Time
+	end      time.Time
+	backward bool
+	buf      []*logging.Entry
+	done     bool
+	// boundary is the timestamp the last batch ended at, which the next
+	// batch starts at again, and seen the insert ids of the entries at
+	// boundary that were already returned.
+	boundary time.Time
+	seen     map[string]bool
+}
+
+func (it *lokiIterator) Next() (*logging.Entry, error) {
+	for len(it.buf) == 0 {
+		if it.done {
+			return nil, iterator.Done
+		}
+		if err := it.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	entry := it.buf[0]
+	it.buf = it.buf[1:]
+	return entry, nil
+}
+
+// fetch requests the next batch of entries starting at it.start, or
+// ending at it.end when iterating backward.
+func (it *lokiIterator) fetch() error {
+	direction := "forward"
+	if it.backward {
+		direction = "backward"
+	}
+	v := url.Values{}
+	v.Set("query", it.logQL)
+	v.Set("start", strconv.FormatInt(it.start.UnixNano(), 10))
+	v.Set("limit", strconv.Itoa(LokiBatchSize))
+	v.Set("direction", direction)
+	if !it.end.IsZero() {
+		// Loki's end parameter is exclusive.
+		v.Set("end", strconv.FormatInt(it.end.Add(time.Nanosecond).UnixNano(), 10))
+	}
+	u := fmt.Sprintf("%s/loki/api/v1/query_range?%s", it.backend.baseURL, v.Encode())
+
+	req, err := http.NewRequestWithContext(it.ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return xerrors.Errorf("error building loki request: %w", err)
+	}
+	resp, err := it.backend.client.Do(req)
+	if err != nil {
+		return xerrors.Errorf("error querying loki: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error querying loki: unexpected status %s", resp.Status)
+	}
+	var body lokiQueryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return xerrors.Errorf("error decoding loki response: %w", err)
+	}
+
+	var entries []*logging.Entry
+	for _, r := range body.Data.Result {
+		for _, value := range r.Values {
+			ns, err := strconv.ParseInt(value[0], 10, 64)
+			if err != nil {
+				return xerrors.Errorf("invalid loki timestamp %q: %w", value[0], err)
+			}
+			entry := lokiEntry(r.Stream, time.Unix(0, ns), value[1])
+			entry.InsertID = lokiInsertID(r.Stream, value[0], value[1])
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if it.backward {
+			return entries[i].Timestamp.After(entries[j].Timestamp)
+		}
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+
+	// Batches overlap at the timestamp the previous one ended at, so that
+	// entries sharing it are not lost when the limit falls between them.
+	// Entries already returned are skipped.
+	fresh := make([]*logging.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if !it.seen[entry.InsertID] {
+			fresh = append(fresh, entry)
+		}
+	}
+	it.buf = fresh
+	if len(entries) < LokiBatchSize {
+		it.done = true
+		return nil
+	}
+	last := entries[len(entries)-1].Timestamp
+	if !last.Equal(it.boundary) {
+		it.boundary, it.seen = last, make(map[string]bool)
+	}
+	for _, entry := range entries {
+		if entry.Timestamp.Equal(last) {
+			it.seen[entry.InsertID] = true
+		}
+	}
+	step := time.Duration(0)
+	if len(fresh) == 0 {
+		// More entries share the timestamp than fit in a batch, which
+		// Loki cannot page through, so step past it.
+		step = time.Nanosecond
+	}
+	if it.backward {
+		// A nanosecond is added to it.end when the request is built, as
+		// Loki's end parameter is exclusive.
+		it.end = last.Add(-step)
+	} else {
+		// Loki's start parameter is inclusive.
+		it.start = last.Add(step)
+	}
+	return nil
+}
+
+// lokiEntry converts a Loki log line and its stream labels into a logging
+// Entry shaped like the ones returned by stackdriver.
+func lokiEntry(stream map[string]string, ts time.Time, line string) *logging.Entry {
+	return &logging.Entry{
+		Timestamp: ts,
+		Payload:   line,
+		LogName:   stream[LokiStreamLabel],
+		Labels: map[string]string{
+			StackdriverBuildIDLabel: stream[LokiBuildIDLabel],
+			StackdriverPullLabel:    stream[LokiPullLabel],
+			TektonPipelineNameLabel: stream[LokiPipelineNameLabel],
+			TektonTaskNameLabel:     stream[LokiTaskNameLabel],
+		},
+		Resource: &mrpb.MonitoredResource{
+			Type: StackdriverContainerResourceType,
+			Labels: map[string]string{
+				StackdriverContainerNameLabel: stream[LokiContainerLabel],
+			},
+		},
+	}
+}
+
+// lokiInsertID derives a stable identifier for a log line, since Loki
+// does not assign one the way stackdriver does.
+func lokiInsertID(stream map[string]string, ts, line string) string {
+	keys := make([]string, 0, len(stream))
+	for k := range stream {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha1.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s=%s,", k, stream[k])
+	}
+	fmt.Fprintf(h, "\x00%s\x00%s", ts, line)
+	return fmt.Sprintf("%x", h.Sum(nil))[:16]
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/api/iterator"
+)
+
+func TestLokiBackend(t *testing.T) {
+	var queries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/loki/api/v1/query_range" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		queries = append(queries, r.URL.Query().Get("query"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "streams",
+				"result": []interface{}{
+					map[string]interface{}{
+						"stream": map[string]string{
+							"container":           "step-build",
+							"stream":              "stdout",
+							"tekton_dev_pipeline": "plumbing-ci",
+							"tekton_dev_task":     "build",
+						},
+						"values": [][2]string{
+							{"1577977447000000000", "second"},
+						},
+					},
+					map[string]interface{}{
+						"stream": map[string]string{
+							"container": "step-clone",
+							"stream":    "stderr",
+						},
+						"values": [][2]string{
+							{"1577977445000000000", "first"},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	b := NewLokiBackend(srv.URL+"/", srv.Client())
+	it := b.Entries(context.Background(), &Query{Namespace: "default", BuildID: "12345"})
+
+	var messages, containers []string
+	for {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error iterating entries: %v", err)
+		}
+		messages = append(messages, entry.Payload.(string))
+		containers = append(containers, extractContainerName(entry))
+	}
+
+	if len(queries) != 1 || queries[0] != `{namespace="default", prow_k8s_io_build_id="12345"}` {
+		t.Errorf("unexpected queries sent to loki: %v", queries)
+	}
+	if len(messages) != 2 || messages[0] != "first" || messages[1] != "second" {
+		t.Errorf("expected entries in timestamp order but received %v", messages)
+	}
+	if len(containers) != 2 || containers[0] != "step-clone" || containers[1] != "step-build" {
+		t.Errorf("unexpected container names %v", containers)
+	}
+}
+
+func TestLokiBackendBuilds(t *testing.T) {
+	var directions []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		directions = append(directions, r.URL.Query().Get("direction"))
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "streams",
+				"result": []interface{}{
+					map[string]interface{}{
+						"stream": map[string]string{
+							"prow_k8s_io_build_id":  "12345",
+							"prow_k8s_io_refs_pull": "42",
+							"tekton_dev_pipeline":   "plumbing-ci",
+						},
+						"values": [][2]string{
+							{"1577977445000000000", "first"},
+							{"1577977447000000000", "second"},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	b := NewLokiBackend(srv.URL, srv.Client())
+	it := b.Builds(context.Background(), &IndexQuery{Namespace: "default"})
+
+	var messages []string
+	for {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error iterating entries: %v", err)
+		}
+		messages = append(messages, entry.Payload.(string))
+		if entry.Labels[StackdriverBuildIDLabel] != "12345" || entry.Labels[StackdriverPullLabel] != "42" {
+			t.Errorf("unexpected labels %v", entry.Labels)
+		}
+	}
+
+	if len(directions) != 1 || directions[0] != "backward" {
+		t.Errorf("expected a single backward query but received %v", directions)
+	}
+	if len(messages) != 2 || messages[0] != "second" || messages[1] != "first" {
+		t.Errorf("expected newest entries first but received %v", messages)
+	}
+}
+
+func TestLokiBackendSharedTimestamps(t *testing.T) {
+	// A batch worth of entries, where the batch limit falls between the
+	// entries logged at the last timestamp.
+	base := time.Now().Add(-time.Hour).UnixNano()
+	var values [][2]string
+	for i := 0; i < LokiBatchSize-1; i++ {
+		values = append(values, [2]string{strconv.FormatInt(base+int64(i), 10), "line " + strconv.Itoa(i)})
+	}
+	for _, line := range []string{"a", "b", "c"} {
+		values = append(values, [2]string{strconv.FormatInt(base+int64(LokiBatchSize), 10), line})
+	}
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		var page [][2]string
+		for _, v := range values {
+			if ts, _ := strconv.ParseInt(v[0], 10, 64); ts >= start && len(page) < limit {
+				page = append(page, v)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "success",
+			"data": map[string]interface{}{
+				"resultType": "streams",
+				"result": []interface{}{
+					map[string]interface{}{
+						"stream": map[string]string{"container": "step-build"},
+						"values": page,
+					},
+				},
+			},
+		})
+	}))
+	defer srv.Close()
+
+	b := NewLokiBackend(srv.URL, srv.Client())
+	it := b.Entries(context.Background(), &Query{Namespace: "default", BuildID: "12345"})
+	seen := make(map[string]int)
+	for {
+		entry, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error iterating entries: %v", err)
+		}
+		seen[entry.Payload.(string)]++
+	}
+	if len(seen) != len(values) {
+		t.Errorf("expected %d entries received %d", len(values), len(seen))
+	}
+	for line, n := range seen {
+		if n != 1 {
+			t.Errorf("expected %q once received it %d times", line, n)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests received %d", requests)
+	}
+}
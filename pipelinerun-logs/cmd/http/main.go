@@ -5,10 +5,10 @@ import (
 	"flag"
 	"log"
 	"os"
+	"os/signal"
 	"path"
+	"syscall"
 
-	"cloud.google.com/go/logging"
-	"cloud.google.com/go/logging/logadmin"
 	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
 )
 
@@ -23,26 +23,46 @@ func main() {
 		return
 	}
 
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
 
-	client, err := logging.NewClient(ctx, conf.Project)
+	backend, err := NewBackend(ctx, conf)
 	if err != nil {
-		log.Fatalf("failed to create client: %v", err)
-	}
-	client.OnError = func(err error) {
-		log.Printf("client.OnError: %v", err)
+		log.Fatalf("failed to create backend: %v", err)
 	}
+	backend = NewMeteredBackend(backend, conf.Backend)
 
-	adminClient, err := logadmin.NewClient(ctx, conf.Project)
+	cache, err := NewCache(conf)
 	if err != nil {
-		log.Fatalf("failed to create adminClient: %v", err)
+		log.Fatalf("failed to create cache: %v", err)
+	}
+	if cache != nil {
+		backend = NewCachingBackend(backend, cache, conf)
 	}
 
 	// When building with "ko", templates is deployed under KO_DATA_PATH
 	// If KO_DATA_PATH is not defined, the path will be a relatove one
 	basePath := os.Getenv("KO_DATA_PATH")
-	entries := path.Join(basePath, "templates/entries.html")
+	templates := path.Join(basePath, "templates")
+
+	server := NewServer(conf, backend, templates)
+
+	auth, err := NewAuthenticator(ctx, conf)
+	if err != nil {
+		log.Fatalf("failed to create authenticator: %v", err)
+	}
+	if auth != nil {
+		key, err := readSecretFile(conf.SessionSecretFile)
+		if err != nil {
+			log.Fatalf("failed to read session secret: %v", err)
+		}
+		if len(key) < MinSessionKeyLength {
+			log.Fatalf("session secret must be at least %d bytes long", MinSessionKeyLength)
+		}
+		server.EnableAuth(auth, []byte(key))
+	}
 
-	server := NewServer(conf, client, adminClient, entries)
-	server.Start()
+	if err := server.Run(ctx); err != nil {
+		log.Fatalf("%v", err)
+	}
 }
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	TruncationEntries = "entries"
+	TruncationIndex   = "index"
+
+	ErrorBackend  = "backend"
+	ErrorTimeout  = "timeout"
+	ErrorCanceled = "canceled"
+	ErrorParse    = "parse"
+	ErrorWrite    = "write"
+)
+
+var (
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipelinerun_logs_request_duration_seconds",
+		Help:    "Time taken to serve http requests, by handler and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler", "code"})
+
+	backendQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "pipelinerun_logs_backend_query_duration_seconds",
+		Help:    "Time taken by log backend queries to return their first entry or end, by backend and query (entries or builds).",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend", "query"})
+
+	entriesFetched = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipelinerun_logs_entries_fetched_total",
+		Help: "Number of log entries read from the log backend.",
+	}, []string{"backend"})
+
+	truncations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipelinerun_logs_truncations_total",
+		Help: "Number of times entries were dropped for reaching a limit, by limit (entries or index).",
+	}, []string{"limit"})
+
+	errorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pipelinerun_logs_errors_total",
+		Help: "Number of errors, by type (backend, timeout, canceled, parse or write).",
+	}, []string{"type"})
+)
+
+func init() {
+	prometheus.MustRegister(requestDuration, backendQueryDuration, entriesFetched, truncations, errorsTotal)
+}
+
+// backendErrorType classifies an error returned by a backend query.
+func backendErrorType(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorCanceled
+	}
+	return ErrorBackend
+}
+
+// MeteredBackend records the latency, entries and errors of the queries
+// made to the wrapped Backend.
+type MeteredBackend struct {
+	backend Backend
+	name    string
+}
+
+// NewMeteredBackend wraps backend, labelling its metrics with name.
+func NewMeteredBackend(backend Backend, name string) *MeteredBackend {
+	return &MeteredBackend{backend: backend, name: name}
+}
+
+func (b *MeteredBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	return b.meter("entries", b.backend.Entries(ctx, query))
+}
+
+func (b *MeteredBackend) Builds(ctx context.Context, query *IndexQuery) EntryIterator {
+	return b.meter("builds", b.backend.Builds(ctx, query))
+}
+
+func (b *MeteredBackend) meter(query string, iter EntryIterator) EntryIterator {
+	return &meteredIterator{
+		EntryIterator: iter,
+		start:         time.Now(),
+		duration:      backendQueryDuration.WithLabelValues(b.name, query),
+		entries:       entriesFetched.WithLabelValues(b.name),
+	}
+}
+
+// meteredIterator observes the query duration once the first entry is
+// returned, since backends fetch entries lazily and callers often stop
+// iterating early.
+type meteredIterator struct {
+	EntryIterator
+	start    time.Time
+	observed bool
+	duration prometheus.Observer
+	entries  prometheus.Counter
+}
+
+func (it *meteredIterator) Next() (*logging.Entry, error) {
+	entry, err := it.EntryIterator.Next()
+	if !it.observed {
+		it.observed = true
+		it.duration.Observe(time.Since(it.start).Seconds())
+	}
+	switch {
+	case err == nil:
+		it.entries.Inc()
+	case err != iterator.Done:
+		errorsTotal.WithLabelValues(backendErrorType(err)).Inc()
+	}
+	return entry, err
+}
+
+// statusRecorder captures the status code written by a handler. It keeps
+// the http.Flusher of the underlying writer so that streams still work.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.code == 0 {
+			r.code = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// instrument records the duration of the requests served by h. Requests
+// are given the configured request timeout unless they are long-lived
+// streams, which are instead cancelled when the server shuts down.
+func (s *Server) instrument(handler string, stream bool, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx := r.Context()
+		if stream {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithCancel(ctx)
+			defer cancel()
+			stop := context.AfterFunc(s.stopping, cancel)
+			defer stop()
+		} else if s.conf.RequestTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, s.conf.RequestTimeout)
+			defer cancel()
+		}
+
+		rec := &statusRecorder{ResponseWriter: w}
+		h(rec, r.WithContext(ctx))
+		if rec.code == 0 {
+			rec.code = http.StatusOK
+		}
+		requestDuration.WithLabelValues(handler, strconv.Itoa(rec.code)).Observe(time.Since(start).Seconds())
+	}
+}
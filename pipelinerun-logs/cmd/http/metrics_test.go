@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+	"google.golang.org/api/iterator"
+)
+
+func TestMeteredBackend(t *testing.T) {
+	b := NewMeteredBackend(NewFileBackend("testdata/logs"), "metered-test")
+	it := b.Entries(context.Background(), &Query{Namespace: "default", BuildID: "12345"})
+	var count int
+	for {
+		_, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+	}
+	if got := testutil.ToFloat64(entriesFetched.WithLabelValues("metered-test")); got != float64(count) {
+		t.Errorf("expected %d entries fetched but received %v", count, got)
+	}
+	if got := testutil.CollectAndCount(backendQueryDuration, "pipelinerun_logs_backend_query_duration_seconds"); got == 0 {
+		t.Error("expected the backend query duration to be observed")
+	}
+}
+
+func TestBackendErrorType(t *testing.T) {
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{{
+		err:      errors.New("boom"),
+		expected: ErrorBackend,
+	}, {
+		err:      context.DeadlineExceeded,
+		expected: ErrorTimeout,
+	}, {
+		err:      context.Canceled,
+		expected: ErrorCanceled,
+	}} {
+		if got := backendErrorType(tc.err); got != tc.expected {
+			t.Errorf("expected %v to be a %s error but received %s", tc.err, tc.expected, got)
+		}
+	}
+}
+
+func TestInstrument(t *testing.T) {
+	conf := &config.Config{Namespace: "default", RequestTimeout: time.Minute}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	var deadline bool
+	h := s.instrument("instrument-test", false, func(w http.ResponseWriter, r *http.Request) {
+		_, deadline = r.Context().Deadline()
+		w.WriteHeader(http.StatusTeapot)
+	})
+	h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if !deadline {
+		t.Error("expected request context to have a deadline")
+	}
+	if got := testutil.CollectAndCount(requestDuration.MustCurryWith(map[string]string{"handler": "instrument-test", "code": "418"})); got != 1 {
+		t.Errorf("expected request duration to be observed with the status code but received %d series", got)
+	}
+
+	stream := s.instrument("instrument-stream-test", true, func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); ok {
+			t.Error("expected stream context to have no deadline")
+		}
+		if _, ok := w.(http.Flusher); !ok {
+			t.Error("expected stream response writer to be a flusher")
+		}
+		s.stop()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+			t.Error("expected stream context to be cancelled on shutdown")
+		}
+	})
+	stream(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/stream", nil))
+}
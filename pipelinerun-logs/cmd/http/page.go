@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+)
+
+// MaxPageHistory is the number of previous page cursors kept in a page
+// token, which bounds the size of page urls.
+const MaxPageHistory = 10
+
+// pageToken is the decoded form of the opaque page query parameter. It
+// holds the stream cursor a page starts after, along with the cursors of
+// up to MaxPageHistory previous pages so that a prev link can be built
+// without querying the backend backwards. Once older cursors are dropped
+// the prev link of the oldest remembered page falls back to the first page.
+type pageToken struct {
+	Cursor  string   `json:"c,omitempty"`
+	History []string `json:"h,omitempty"`
+}
+
+// parsePageToken decodes a page token. An empty string is the first page.
+func parsePageToken(s string) (*pageToken, error) {
+	t := &pageToken{}
+	if s == "" {
+		return t, nil
+	}
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid page token: %w", err)
+	}
+	if err := json.Unmarshal(b, t); err != nil {
+		return nil, xerrors.Errorf("invalid page token: %w", err)
+	}
+	return t, nil
+}
+
+// String encodes the token for use in a url.
+func (t *pageToken) String() string {
+	b, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// isFirst reports whether the token points at the first page.
+func (t *pageToken) isFirst() bool {
+	return t.Cursor == "" && len(t.History) == 0
+}
+
+// next returns the token of the page starting after cursor.
+func (t *pageToken) next(cursor string) *pageToken {
+	history := append(append([]string{}, t.History...), t.Cursor)
+	if len(history) > MaxPageHistory {
+		history = history[len(history)-MaxPageHistory:]
+	}
+	return &pageToken{
+		Cursor:  cursor,
+		History: history,
+	}
+}
+
+// prev returns the token of the previous page or nil on the first page.
+func (t *pageToken) prev() *pageToken {
+	if len(t.History) == 0 {
+		if t.Cursor == "" {
+			return nil
+		}
+		// The history was capped, go back to the first page.
+		return &pageToken{}
+	}
+	return &pageToken{
+		Cursor:  t.History[len(t.History)-1],
+		History: t.History[:len(t.History)-1],
+	}
+}
+
+// entriesPage is a window of at most PageSize log entries of a build.
+type entriesPage struct {
+	Entries      []RenderableEntry
+	PipelineName string
+	// Cursor points after the last entry of the page.
+	Cursor string
+	// Next and Prev are nil on the last and first page respectively.
+	Next *pageToken
+	Prev *pageToken
+}
+
+// fetchPage reads a page of entries from the backend, starting after the
+// token's cursor. Only a single page of entries is held in memory.
+func (s *Server) fetchPage(ctx context.Context, query *Query, token *pageToken) (*entriesPage, error) {
+	cursor, err := parseStreamCursor(token.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	q := *query
+	q.Since = cursor.ts
+	iter := s.backend.Entries(ctx, &q)
+
+	p := &entriesPage{
+		Entries: make([]RenderableEntry, 0),
+		Prev:    token.prev(),
+	}
+	for {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("error iterating log entries: %w", err)
+		}
+		if cursor.seenEntry(entry) {
+			continue
+		}
+		re, err := s.structureEntry(entry)
+		if err != nil {
+			return nil, xerrors.Errorf("error structuring log entry: %w", err)
+		}
+		if p.PipelineName == "" {
+			p.PipelineName = entry.Labels[TektonPipelineNameLabel]
+		}
+		if !query.Filter.Matches(entry, re) {
+			cursor.add(entry)
+			continue
+		}
+		if len(p.Entries) == s.conf.PageSize {
+			// There is at least one more entry than fits on this page.
+			p.Next = token.next(cursor.String())
+			break
+		}
+		cursor.add(entry)
+		p.Entries = append(p.Entries, *re)
+	}
+	p.Cursor = cursor.String()
+	return p, nil
+}
+
+// pageURL returns the url of the page identified by token, keeping the
+// rest of the request's query parameters.
+func pageURL(u *url.URL, token *pageToken) string {
+	v := u.Query()
+	v.Del("page")
+	if !token.isFirst() {
+		v.Set("page", token.String())
+	}
+	return u.Path + "?" + v.Encode()
+}
+
+// setPageLinks adds Link headers pointing at the next and previous pages.
+func setPageLinks(w http.ResponseWriter, u *url.URL, p *entriesPage) {
+	if p.Next != nil {
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="next"`, pageURL(u, p.Next)))
+	}
+	if p.Prev != nil {
+		w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="prev"`, pageURL(u, p.Prev)))
+	}
+}
+
+// EntriesAPIResponse is the JSON document served by the entries API.
+type EntriesAPIResponse struct {
+	BuildID      string            `json:"buildId"`
+	PipelineName string            `json:"pipeline,omitempty"`
+	Entries      []RenderableEntry `json:"entries"`
+	NextPage     string            `json:"nextPage,omitempty"`
+	PrevPage     string            `json:"prevPage,omitempty"`
+	Truncated    bool              `json:"truncated"`
+}
+
+// serveEntriesAPI serves a page of log entries along with the tokens of
+// the neighbouring pages as a JSON document.
+func (s *Server) serveEntriesAPI(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s?%s", r.URL.Path, r.URL.RawQuery)
+
+	params, query, ok := s.queryForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := parsePageToken(r.URL.Query().Get("page"))
+	if err != nil {
+		log.Printf("%v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	p, err := s.fetchPage(r.Context(), query, token)
+	if err != nil {
+		log.Printf("%v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	resp := &EntriesAPIResponse{
+		BuildID:      params.buildID,
+		PipelineName: p.PipelineName,
+		Entries:      p.Entries,
+		Truncated:    p.Next != nil,
+	}
+	if p.Next != nil {
+		resp.NextPage = p.Next.String()
+	}
+	if p.Prev != nil {
+		resp.PrevPage = p.Prev.String()
+	}
+
+	setPageLinks(w, r.URL, p)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("error writing entries: %v", err)
+		errorsTotal.WithLabelValues(ErrorWrite).Inc()
+	}
+}
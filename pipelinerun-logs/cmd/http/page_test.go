@@ -0,0 +1,183 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+func TestPageToken(t *testing.T) {
+	first, err := parsePageToken("")
+	if err != nil {
+		t.Fatalf("unexpected error parsing empty token: %v", err)
+	}
+	if !first.isFirst() || first.prev() != nil {
+		t.Errorf("expected empty token to be the first page")
+	}
+
+	second := first.next("2020-01-02T15:04:05Z/a")
+	third := second.next("2020-01-02T15:04:06Z/b")
+	decoded, err := parsePageToken(third.String())
+	if err != nil {
+		t.Fatalf("unexpected error parsing token: %v", err)
+	}
+	if decoded.Cursor != third.Cursor {
+		t.Errorf("expected cursor %q received %q", third.Cursor, decoded.Cursor)
+	}
+	if prev := decoded.prev(); prev == nil || prev.Cursor != second.Cursor {
+		t.Errorf("expected prev page to be %+v received %+v", second, prev)
+	}
+	if prev := decoded.prev().prev(); prev == nil || !prev.isFirst() {
+		t.Errorf("expected prev of second page to be the first page received %+v", prev)
+	}
+
+	token := first
+	for i := 0; i < 3*MaxPageHistory; i++ {
+		token = token.next(fmt.Sprintf("2020-01-02T15:04:05Z/%d", i))
+	}
+	if len(token.History) != MaxPageHistory {
+		t.Errorf("expected %d cursors in the page history received %d", MaxPageHistory, len(token.History))
+	}
+	for i := 0; i < MaxPageHistory; i++ {
+		token = token.prev()
+	}
+	if token == nil || token.isFirst() {
+		t.Fatalf("expected the oldest remembered page received %+v", token)
+	}
+	if prev := token.prev(); prev == nil || !prev.isFirst() {
+		t.Errorf("expected prev of the oldest remembered page to be the first page received %+v", prev)
+	}
+
+	if _, err := parsePageToken("not a token"); err == nil {
+		t.Error("expected error parsing invalid token")
+	}
+}
+
+func TestFetchPage(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		buildID     string
+		pages       [][]string
+	}{{
+		description: "splits entries into pages",
+		buildID:     "12345",
+		pages: [][]string{
+			{"Cloning into 'plumbing'...", "ok  \tgithub.com/tektoncd/plumbing/pipelinerun-logs/cmd/http\t0.008s"},
+			{"unit tests finished"},
+		},
+	}, {
+		description: "does not repeat or skip entries sharing a timestamp across pages",
+		buildID:     "67890",
+		pages: [][]string{
+			{"line 1", "line 2"},
+			{"line 3"},
+		},
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			conf := &config.Config{Project: "FooProject", Namespace: "default", PageSize: 2}
+			s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+			query := &Query{Namespace: "default", BuildID: tc.buildID}
+
+			token := &pageToken{}
+			for i, expected := range tc.pages {
+				p, err := s.fetchPage(t.Context(), query, token)
+				if err != nil {
+					t.Fatalf("unexpected error fetching page %d: %v", i, err)
+				}
+				var messages []string
+				for _, e := range p.Entries {
+					messages = append(messages, e.Message)
+				}
+				if strings.Join(messages, "|") != strings.Join(expected, "|") {
+					t.Errorf("expected page %d to contain %q received %q", i, expected, messages)
+				}
+				if last := i == len(tc.pages)-1; last != (p.Next == nil) {
+					t.Errorf("expected next page on page %d: %t", i, !last)
+				}
+				if (i == 0) != (p.Prev == nil) {
+					t.Errorf("expected prev page on page %d: %t", i, i != 0)
+				}
+				token = p.Next
+			}
+		})
+	}
+}
+
+func TestServeLogPages(t *testing.T) {
+	conf := &config.Config{Project: "FooProject", Namespace: "default", PageSize: 2}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	w := httptest.NewRecorder()
+	s.serveLog(w, httptest.NewRequest(http.MethodGet, "/?namespace=default&buildid=12345", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d received %d", http.StatusOK, w.Code)
+	}
+	if !strings.Contains(w.Body.String(), "more log entries than fit on one page") {
+		t.Error("expected truncated banner on first page")
+	}
+	next := w.Header().Get("Link")
+	if !strings.HasPrefix(next, "</?") || !strings.HasSuffix(next, `>; rel="next"`) {
+		t.Fatalf("expected next link header received %q", next)
+	}
+
+	w = httptest.NewRecorder()
+	s.serveLog(w, httptest.NewRequest(http.MethodGet, strings.TrimSuffix(strings.TrimPrefix(next, "<"), `>; rel="next"`), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d received %d", http.StatusOK, w.Code)
+	}
+	if strings.Contains(w.Body.String(), "more log entries than fit on one page") {
+		t.Error("expected no truncated banner on last page")
+	}
+	if !strings.Contains(w.Body.String(), "unit tests finished") {
+		t.Error("expected last page to contain the last entry")
+	}
+	if links := w.Header().Values("Link"); len(links) != 1 || links[0] != `</?buildid=12345&namespace=default>; rel="prev"` {
+		t.Errorf("expected prev link to the first page received %q", links)
+	}
+
+	w = httptest.NewRecorder()
+	s.serveLog(w, httptest.NewRequest(http.MethodGet, "/?namespace=default&buildid=12345&page=garbage", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for invalid page token received %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestServeEntriesAPI(t *testing.T) {
+	conf := &config.Config{Project: "FooProject", Namespace: "default", PageSize: 2}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	var pages []EntriesAPIResponse
+	v := url.Values{"namespace": {"default"}, "buildid": {"12345"}}
+	for {
+		w := httptest.NewRecorder()
+		s.serveEntriesAPI(w, httptest.NewRequest(http.MethodGet, "/api/v1/entries?"+v.Encode(), nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status %d received %d", http.StatusOK, w.Code)
+		}
+		var resp EntriesAPIResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("error decoding response: %v", err)
+		}
+		pages = append(pages, resp)
+		if resp.NextPage == "" {
+			break
+		}
+		v.Set("page", resp.NextPage)
+	}
+
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages received %d", len(pages))
+	}
+	if !pages[0].Truncated || pages[0].PrevPage != "" || pages[0].PipelineName != "plumbing-ci" || len(pages[0].Entries) != 2 {
+		t.Errorf("unexpected first page %+v", pages[0])
+	}
+	if pages[1].Truncated || pages[1].PrevPage == "" || len(pages[1].Entries) != 1 {
+		t.Errorf("unexpected second page %+v", pages[1])
+	}
+}
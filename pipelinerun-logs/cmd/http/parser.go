@@ -0,0 +1,334 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+)
+
+// PayloadParser turns the payload of a log entry into a RenderableEntry.
+// Only the fields derived from the payload are set, the rest are filled
+// in from the entry itself. Parse returns false if the payload is not in
+// the parser's format.
+type PayloadParser interface {
+	Parse(payload string) (*RenderableEntry, bool)
+}
+
+// payloadParsers are tried in order until one of them accepts a payload.
+// The text parser accepts everything so it must remain last.
+var payloadParsers = []PayloadParser{
+	zapParser{},
+	logrusParser{},
+	test2jsonParser{},
+	klogParser{},
+	ansiParser{},
+	textParser{},
+}
+
+// parsePayload runs the payload of an entry through payloadParsers.
+// Structured payloads are JSON encoded first.
+func parsePayload(entry *logging.Entry) (*RenderableEntry, error) {
+	payload, isString := entry.Payload.(string)
+	if !isString {
+		b, err := json.Marshal(entry.Payload)
+		if err != nil {
+			return nil, xerrors.Errorf("error encoding entry payload: %w", err)
+		}
+		payload = string(b)
+	}
+	for _, p := range payloadParsers {
+		if re, ok := p.Parse(payload); ok {
+			return re, nil
+		}
+	}
+	return &RenderableEntry{Message: payload}, nil
+}
+
+// jsonObject decodes a payload holding a JSON object.
+func jsonObject(payload string) (map[string]json.RawMessage, bool) {
+	if !strings.HasPrefix(strings.TrimSpace(payload), "{") {
+		return nil, false
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(payload), &obj); err != nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// jsonString returns the string value of a key of a JSON object, or the
+// raw JSON if the value is not a string.
+func jsonString(obj map[string]json.RawMessage, key string) string {
+	raw, ok := obj[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return string(raw)
+	}
+	return s
+}
+
+// zapParser reads zap's JSON encoding, either as logged by zap or in the
+// struct form stackdriver stores jsonPayloads in.
+type zapParser struct{}
+
+func (zapParser) Parse(payload string) (*RenderableEntry, bool) {
+	obj, ok := jsonObject(payload)
+	if !ok {
+		return nil, false
+	}
+	if _, ok := obj["fields"]; ok {
+		var ep EntryPayload
+		if err := json.Unmarshal([]byte(payload), &ep); err != nil {
+			return nil, false
+		}
+		return &RenderableEntry{
+			Message:    ep.Fields.Msg.Kind.StringValue,
+			Caller:     ep.Fields.Caller.Kind.StringValue,
+			Level:      ep.Fields.Level.Kind.StringValue,
+			Stacktrace: ep.Fields.Stacktrace.Kind.StringValue,
+		}, true
+	}
+	_, hasMsg := obj["msg"]
+	_, hasTS := obj["ts"]
+	_, hasCaller := obj["caller"]
+	if !hasMsg || !(hasTS || hasCaller) {
+		return nil, false
+	}
+	return &RenderableEntry{
+		Message:    jsonString(obj, "msg"),
+		Caller:     jsonString(obj, "caller"),
+		Level:      jsonString(obj, "level"),
+		Stacktrace: jsonString(obj, "stacktrace"),
+	}, true
+}
+
+// logrusParser reads logrus' JSONFormatter output. Fields other than the
+// standard ones are appended to the message as key=value pairs.
+type logrusParser struct{}
+
+var logrusStandardFields = map[string]bool{
+	"msg":   true,
+	"level": true,
+	"time":  true,
+	"func":  true,
+	"file":  true,
+}
+
+func (logrusParser) Parse(payload string) (*RenderableEntry, bool) {
+	obj, ok := jsonObject(payload)
+	if !ok {
+		return nil, false
+	}
+	for _, key := range []string{"msg", "level", "time"} {
+		if _, ok := obj[key]; !ok {
+			return nil, false
+		}
+	}
+	var extra []string
+	for key := range obj {
+		if !logrusStandardFields[key] {
+			extra = append(extra, key)
+		}
+	}
+	sort.Strings(extra)
+	msg := jsonString(obj, "msg")
+	for _, key := range extra {
+		msg += fmt.Sprintf(" %s=%s", key, jsonString(obj, key))
+	}
+	level := jsonString(obj, "level")
+	if level == "warning" {
+		level = "warn"
+	}
+	return &RenderableEntry{
+		Message: msg,
+		Caller:  jsonString(obj, "file"),
+		Level:   level,
+	}, true
+}
+
+const (
+	TestResultPass = "pass"
+	TestResultFail = "fail"
+	TestResultSkip = "skip"
+)
+
+// test2jsonEvent is an event emitted by go test -json.
+type test2jsonEvent struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed *float64
+	Output  string
+}
+
+var test2jsonActions = map[string]bool{
+	"start":        true,
+	"run":          true,
+	"pause":        true,
+	"cont":         true,
+	"bench":        true,
+	"output":       true,
+	TestResultPass: true,
+	TestResultFail: true,
+	TestResultSkip: true,
+}
+
+var testFailureOutput = regexp.MustCompile(`^\s*(--- FAIL|FAIL\b|panic:)`)
+
+// test2jsonParser reads go test -json events. Tests that pass, fail or are
+// skipped get a result entry, and failures are logged at error level.
+type test2jsonParser struct{}
+
+func (test2jsonParser) Parse(payload string) (*RenderableEntry, bool) {
+	if _, ok := jsonObject(payload); !ok {
+		return nil, false
+	}
+	var e test2jsonEvent
+	if err := json.Unmarshal([]byte(payload), &e); err != nil || !test2jsonActions[e.Action] {
+		return nil, false
+	}
+	name := e.Test
+	if name == "" {
+		name = e.Package
+	}
+	re := &RenderableEntry{Caller: e.Package, Test: e.Test}
+	switch e.Action {
+	case "output":
+		re.Message = strings.TrimRight(e.Output, "\n")
+		if testFailureOutput.MatchString(re.Message) {
+			re.Level = "error"
+		}
+	case TestResultPass, TestResultFail, TestResultSkip:
+		re.Message = fmt.Sprintf("%s %s", strings.ToUpper(e.Action), name)
+		if e.Elapsed != nil {
+			re.Message += fmt.Sprintf(" (%ss)", strconv.FormatFloat(*e.Elapsed, 'f', 2, 64))
+		}
+		re.TestResult = e.Action
+		if e.Action == TestResultFail {
+			re.Level = "error"
+		}
+	default:
+		re.Message = fmt.Sprintf("%s %s", strings.ToUpper(e.Action), name)
+	}
+	return re, true
+}
+
+// klogLine matches klog's text header,
+// "Lmmdd hh:mm:ss.uuuuuu threadid file:line] msg".
+var klogLine = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}\.\d{6}\s+\d+ ([^ \]]+:\d+)\] (.*)$`)
+
+var klogLevels = map[string]string{
+	"I": "info",
+	"W": "warn",
+	"E": "error",
+	"F": "fatal",
+}
+
+// klogParser reads klog's text format.
+type klogParser struct{}
+
+func (klogParser) Parse(payload string) (*RenderableEntry, bool) {
+	m := klogLine.FindStringSubmatch(strings.TrimRight(payload, "\n"))
+	if m == nil {
+		return nil, false
+	}
+	return &RenderableEntry{
+		Message: m[3],
+		Caller:  m[2],
+		Level:   klogLevels[m[1]],
+	}, true
+}
+
+// ansiSequence matches ANSI CSI escape sequences. Only SGR sequences,
+// ending in "m", affect how text is rendered.
+var ansiSequence = regexp.MustCompile(`\x1b\[([0-9;?]*)([A-Za-z])`)
+
+var ansiColors = []string{"black", "red", "green", "yellow", "blue", "magenta", "cyan", "white"}
+
+// TextSegment is a run of text rendered in the same style.
+type TextSegment struct {
+	Text  string `json:"text"`
+	Color string `json:"color,omitempty"`
+	Bold  bool   `json:"bold,omitempty"`
+}
+
+// ansiParser strips ANSI escape sequences from text payloads, keeping the
+// colors they set as segments of the message for the html page.
+type ansiParser struct{}
+
+func (ansiParser) Parse(payload string) (*RenderableEntry, bool) {
+	if !strings.Contains(payload, "\x1b[") {
+		return nil, false
+	}
+	var (
+		segments []TextSegment
+		message  strings.Builder
+		current  TextSegment
+		styled   bool
+	)
+	flush := func(text string) {
+		if text == "" {
+			return
+		}
+		message.WriteString(text)
+		current.Text = text
+		segments = append(segments, current)
+	}
+	last := 0
+	for _, m := range ansiSequence.FindAllStringSubmatchIndex(payload, -1) {
+		flush(payload[last:m[0]])
+		last = m[1]
+		if payload[m[4]:m[5]] != "m" {
+			continue
+		}
+		params := payload[m[2]:m[3]]
+		if params == "" {
+			params = "0"
+		}
+		for _, p := range strings.Split(params, ";") {
+			code, err := strconv.Atoi(p)
+			if err != nil {
+				continue
+			}
+			switch {
+			case code == 0:
+				current = TextSegment{}
+			case code == 1:
+				current.Bold = true
+			case code == 22:
+				current.Bold = false
+			case code >= 30 && code <= 37:
+				current.Color = ansiColors[code-30]
+			case code >= 90 && code <= 97:
+				current.Color = "bright-" + ansiColors[code-90]
+			case code == 39:
+				current.Color = ""
+			}
+			if current.Color != "" || current.Bold {
+				styled = true
+			}
+		}
+	}
+	flush(payload[last:])
+	re := &RenderableEntry{Message: message.String()}
+	if styled {
+		re.Segments = segments
+	}
+	return re, true
+}
+
+// textParser accepts any payload as a plain message.
+type textParser struct{}
+
+func (textParser) Parse(payload string) (*RenderableEntry, bool) {
+	return &RenderableEntry{Message: payload}, true
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"cloud.google.com/go/logging"
+)
+
+var update = flag.Bool("update", false, "update the golden files of payload parser tests")
+
+// TestParsePayloadFixtures parses every line of testdata/payloads/<format>.log
+// and compares the entries with testdata/payloads/<format>.golden.json.
+func TestParsePayloadFixtures(t *testing.T) {
+	for _, format := range []string{"zap", "logrus", "test2json", "klog", "ansi"} {
+		t.Run(format, func(t *testing.T) {
+			f, err := os.Open(filepath.Join("testdata/payloads", format+".log"))
+			if err != nil {
+				t.Fatalf("error opening fixture: %v", err)
+			}
+			defer f.Close()
+
+			var entries []*RenderableEntry
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				re, err := parsePayload(&logging.Entry{Payload: scanner.Text()})
+				if err != nil {
+					t.Fatalf("unexpected error parsing %q: %v", scanner.Text(), err)
+				}
+				entries = append(entries, re)
+			}
+			if err := scanner.Err(); err != nil {
+				t.Fatalf("error reading fixture: %v", err)
+			}
+
+			got, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				t.Fatalf("error encoding entries: %v", err)
+			}
+			golden := filepath.Join("testdata/payloads", format+".golden.json")
+			if *update {
+				if err := os.WriteFile(golden, append(got, '\n'), 0644); err != nil {
+					t.Fatalf("error updating golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("error reading golden file: %v", err)
+			}
+			if !bytes.Equal(bytes.TrimSpace(got), bytes.TrimSpace(expected)) {
+				t.Errorf("expected entries:\n%s\nreceived:\n%s", expected, got)
+			}
+		})
+	}
+}
+
+func TestParsePayloadFallsBackToText(t *testing.T) {
+	for _, payload := range []string{
+		"Cloning into 'plumbing'...",
+		`{"unrelated":"json"}`,
+		"I0102 is not quite klog",
+	} {
+		re, err := parsePayload(&logging.Entry{Payload: payload})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if re.Message != payload || re.Level != "" || re.Caller != "" {
+			t.Errorf("expected %q to be rendered as plain text but received %+v", payload, re)
+		}
+	}
+}
+
+func TestStructureEntryTestResult(t *testing.T) {
+	s := newIndexTestServer(NewFileBackend("testdata/logs"))
+	re, err := s.structureEntry(&logging.Entry{
+		Payload: `{"Action":"fail","Package":"example.com/foo","Test":"TestBar","Elapsed":0.5}`,
+		Labels:  map[string]string{TektonTaskNameLabel: "unit-tests"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if re.TaskName != "unit-tests" || re.TestResult != TestResultFail || re.Level != "error" || !strings.HasPrefix(re.Message, "FAIL TestBar") {
+		t.Errorf("unexpected entry %+v", re)
+	}
+}
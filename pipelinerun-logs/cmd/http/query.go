@@ -3,10 +3,17 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 const (
 	StackdriverBuildIDLabel = "k8s-pod/prow_k8s_io/build-id"
+	StackdriverPullLabel    = "k8s-pod/prow_k8s_io/refs_pull"
+	LokiBuildIDLabel        = "prow_k8s_io_build_id"
+	LokiClusterLabel        = "cluster"
+	LokiNamespaceLabel      = "namespace"
+	LokiPullLabel           = "prow_k8s_io_refs_pull"
 )
 
 type Query struct {
@@ -14,17 +21,26 @@ type Query struct {
 	Cluster   string
 	Namespace string
 	BuildID   string
+	// Since, if set, restricts the query to entries logged at or after
+	// the given time.
+	Since time.Time
+	// Filter narrows down the entries of the build.
+	Filter Filter
+}
+
+// Start returns the earliest timestamp of entries matching the query, or
+// the zero time if it is unbounded.
+func (q *Query) Start() time.Time {
+	if q.Filter.Start.After(q.Since) {
+		return q.Filter.Start
+	}
+	return q.Since
 }
 
 // Validate ensures that required information for a query is provided
-// and returns an error otherwise.
+// and returns an error otherwise. Project and cluster are only required
+// by the stackdriver backend and are checked when the config is validated.
 func (q *Query) Validate() error {
-	if q.Project == "" {
-		return errors.New("invalid query: missing project")
-	}
-	if q.Cluster == "" {
-		return errors.New("invalid query: missing cluster")
-	}
 	if q.Namespace == "" {
 		return errors.New("invalid query: missing namespace")
 	}
@@ -37,7 +53,7 @@ func (q *Query) Validate() error {
 // ToFilter returns a stackdriver filter string that is populated
 // with data from the query.
 func (q *Query) ToFilter() string {
-	return fmt.Sprintf(`
+	filter := fmt.Sprintf(`
 resource.type=k8s_container
 AND (
 	logName=projects/%s/logs/stderr
@@ -54,4 +70,92 @@ AND labels.%q=%q
 		StackdriverBuildIDLabel,
 		q.BuildID,
 	)
+	if start := q.Start(); !start.IsZero() {
+		filter += fmt.Sprintf("AND timestamp>=%q\n", start.UTC().Format(time.RFC3339Nano))
+	}
+	if !q.Filter.End.IsZero() {
+		filter += fmt.Sprintf("AND timestamp<=%q\n", q.Filter.End.UTC().Format(time.RFC3339Nano))
+	}
+	if q.Filter.Task != "" {
+		filter += fmt.Sprintf("AND labels.%q=%q\n", TektonTaskNameLabel, q.Filter.Task)
+	}
+	if q.Filter.Container != "" {
+		filter += fmt.Sprintf("AND resource.labels.%s=%q\n", StackdriverContainerNameLabel, q.Filter.Container)
+	}
+	// Levels and searches are not pushed down: parsers derive levels and
+	// messages from fields other than jsonPayload.level and jsonPayload.msg,
+	// e.g. klog headers or test2json output, and searches must only match
+	// once secrets are redacted.
+	return filter
+}
+
+// ToLogQL returns a LogQL query that is populated with data from the
+// query. Label names follow promtail's kubernetes pod label mapping, where
+// "prow.k8s.io/build-id" becomes "prow_k8s_io_build_id". Levels are not
+// pushed down since they are only known once a line has been parsed, and
+// searches are not either since they must not match redacted secrets.
+func (q *Query) ToLogQL() string {
+	var matchers []string
+	if q.Cluster != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, LokiClusterLabel, q.Cluster))
+	}
+	matchers = append(matchers,
+		fmt.Sprintf(`%s=%q`, LokiNamespaceLabel, q.Namespace),
+		fmt.Sprintf(`%s=%q`, LokiBuildIDLabel, q.BuildID),
+	)
+	if q.Filter.Task != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, LokiTaskNameLabel, q.Filter.Task))
+	}
+	if q.Filter.Container != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, LokiContainerLabel, q.Filter.Container))
+	}
+	return "{" + strings.Join(matchers, ", ") + "}"
+}
+
+// IndexQuery selects the entries of all recent builds in a namespace, which
+// are aggregated into the build index.
+type IndexQuery struct {
+	Project   string
+	Cluster   string
+	Namespace string
+	// Since restricts the query to entries logged at or after the given
+	// time.
+	Since time.Time
+}
+
+// ToFilter returns a stackdriver filter string matching entries of any
+// build in the query's namespace.
+func (q *IndexQuery) ToFilter() string {
+	return fmt.Sprintf(`
+resource.type=k8s_container
+AND (
+	logName=projects/%s/logs/stderr
+	OR logName=projects/%s/logs/stdout
+)
+AND resource.labels.cluster_name=%q
+AND resource.labels.namespace_name=%q
+AND labels.%q:*
+AND timestamp>=%q
+`,
+		q.Project,
+		q.Project,
+		q.Cluster,
+		q.Namespace,
+		StackdriverBuildIDLabel,
+		q.Since.UTC().Format(time.RFC3339Nano),
+	)
+}
+
+// ToLogQL returns a LogQL selector matching entries of any build in the
+// query's namespace.
+func (q *IndexQuery) ToLogQL() string {
+	var matchers []string
+	if q.Cluster != "" {
+		matchers = append(matchers, fmt.Sprintf(`%s=%q`, LokiClusterLabel, q.Cluster))
+	}
+	matchers = append(matchers,
+		fmt.Sprintf(`%s=%q`, LokiNamespaceLabel, q.Namespace),
+		fmt.Sprintf(`%s=~".+"`, LokiBuildIDLabel),
+	)
+	return "{" + strings.Join(matchers, ", ") + "}"
 }
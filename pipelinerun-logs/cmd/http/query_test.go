@@ -1,8 +1,10 @@
 package main
 
 import (
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -10,22 +12,6 @@ func TestValidate(t *testing.T) {
 		q             Query
 		expectedError string
 	}{{
-		q: Query{
-			Project:   "",
-			Cluster:   "FooCluster",
-			Namespace: "FooNamespace",
-			BuildID:   "123456",
-		},
-		expectedError: "project",
-	}, {
-		q: Query{
-			Project:   "FooProject",
-			Cluster:   "",
-			Namespace: "FooNamespace",
-			BuildID:   "123456",
-		},
-		expectedError: "cluster",
-	}, {
 		q: Query{
 			Project:   "FooProject",
 			Cluster:   "FooCluster",
@@ -48,3 +34,98 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestToLogQL(t *testing.T) {
+	for _, tc := range []struct {
+		q        Query
+		expected string
+	}{{
+		q: Query{
+			Namespace: "FooNamespace",
+			BuildID:   "123456",
+		},
+		expected: `{namespace="FooNamespace", prow_k8s_io_build_id="123456"}`,
+	}, {
+		q: Query{
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			BuildID:   "123456",
+		},
+		expected: `{cluster="FooCluster", namespace="FooNamespace", prow_k8s_io_build_id="123456"}`,
+	}} {
+		if logQL := tc.q.ToLogQL(); logQL != tc.expected {
+			t.Errorf("expected LogQL %s received %s", tc.expected, logQL)
+		}
+	}
+}
+
+func TestToFilterSince(t *testing.T) {
+	q := Query{
+		Project:   "FooProject",
+		Cluster:   "FooCluster",
+		Namespace: "FooNamespace",
+		BuildID:   "123456",
+	}
+	if strings.Contains(q.ToFilter(), "timestamp") {
+		t.Errorf("expected no timestamp restriction in filter %s", q.ToFilter())
+	}
+	q.Since = time.Date(2020, 1, 2, 15, 4, 5, 6, time.UTC)
+	if expected := `timestamp>="2020-01-02T15:04:05.000000006Z"`; !strings.Contains(q.ToFilter(), expected) {
+		t.Errorf("expected filter to contain %s received %s", expected, q.ToFilter())
+	}
+}
+
+func TestFilterPushdown(t *testing.T) {
+	q := Query{
+		Project:   "FooProject",
+		Cluster:   "FooCluster",
+		Namespace: "FooNamespace",
+		BuildID:   "123456",
+		Filter: Filter{
+			Task:      "unit-tests",
+			Container: "step-test",
+			Level:     "panic",
+			Search:    regexp.MustCompile("FAIL"),
+			End:       time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+		},
+	}
+	for _, expected := range []string{
+		`AND timestamp<="2020-01-02T15:04:05Z"`,
+		`AND labels."k8s-pod/tekton_dev/task"="unit-tests"`,
+		`AND resource.labels.container_name="step-test"`,
+	} {
+		if !strings.Contains(q.ToFilter(), expected) {
+			t.Errorf("expected filter to contain %s received %s", expected, q.ToFilter())
+		}
+	}
+	for _, unexpected := range []string{"jsonPayload.level", "jsonPayload.msg", "textPayload"} {
+		if strings.Contains(q.ToFilter(), unexpected) {
+			t.Errorf("expected filter not to contain %s received %s", unexpected, q.ToFilter())
+		}
+	}
+	expected := `{cluster="FooCluster", namespace="FooNamespace", prow_k8s_io_build_id="123456", tekton_dev_task="unit-tests", container="step-test"}`
+	if logQL := q.ToLogQL(); logQL != expected {
+		t.Errorf("expected LogQL %s received %s", expected, logQL)
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	q := IndexQuery{
+		Project:   "FooProject",
+		Cluster:   "FooCluster",
+		Namespace: "FooNamespace",
+		Since:     time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC),
+	}
+	for _, expected := range []string{
+		`AND labels."k8s-pod/prow_k8s_io/build-id":*`,
+		`AND timestamp>="2020-01-02T15:04:05Z"`,
+	} {
+		if !strings.Contains(q.ToFilter(), expected) {
+			t.Errorf("expected filter to contain %s received %s", expected, q.ToFilter())
+		}
+	}
+	expected := `{cluster="FooCluster", namespace="FooNamespace", prow_k8s_io_build_id=~".+"}`
+	if logQL := q.ToLogQL(); logQL != expected {
+		t.Errorf("expected LogQL %s received %s", expected, logQL)
+	}
+}
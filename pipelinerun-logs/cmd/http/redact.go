@@ -0,0 +1,98 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// RedactedText replaces secrets found in log entries.
+const RedactedText = "[REDACTED]"
+
+// builtinRedactPatterns match well known shapes of credentials. If a
+// pattern has a group named "secret" only that group is redacted, so that
+// the context of the secret stays readable.
+var builtinRedactPatterns = []string{
+	// GitHub personal access, OAuth, app and refresh tokens
+	`gh[pousr]_[A-Za-z0-9]{36,}`,
+	`github_pat_[A-Za-z0-9_]{22,}`,
+	// Google API keys and OAuth access tokens
+	`AIza[0-9A-Za-z_\-]{35}`,
+	`ya29\.[0-9A-Za-z_\-]+`,
+	// PEM private keys, such as the one in a GCP service account key.
+	// Log lines may end before the key does, in which case everything
+	// after the header is redacted.
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----|[\s\S]*)`,
+	// bearer and basic credentials in Authorization headers, such as
+	// curl -H "Authorization: Bearer ..." or a dumped http request
+	`(?i)\b(?:proxy-)?authorization["']?\s*[:=]\s*["']?(?:bearer|basic|token)\s+(?P<secret>[A-Za-z0-9\-._~+/]{8,}=*)`,
+}
+
+// Redactor replaces secrets in log entries before they are served.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor hiding the built-in credential shapes as
+// well as anything matching the given patterns.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, p := range append(builtinRedactPatterns, patterns...) {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, err
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// MustNewRedactor is like NewRedactor but panics if a pattern does not
+// compile. Patterns from the config have already been validated.
+func MustNewRedactor(patterns []string) *Redactor {
+	r, err := NewRedactor(patterns)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Redact returns s with every secret replaced by RedactedText.
+func (r *Redactor) Redact(s string) string {
+	for _, re := range r.patterns {
+		group := re.SubexpIndex("secret")
+		matches := re.FindAllStringSubmatchIndex(s, -1)
+		if matches == nil {
+			continue
+		}
+		var b strings.Builder
+		last := 0
+		for _, m := range matches {
+			start, end := m[0], m[1]
+			if group > 0 && m[2*group] >= 0 {
+				start, end = m[2*group], m[2*group+1]
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(RedactedText)
+			last = end
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+	}
+	return s
+}
+
+// RedactEntry redacts the free-form text of a structured entry. Styled
+// segments are dropped if a secret spans more than one of them, leaving
+// only the redacted message.
+func (r *Redactor) RedactEntry(re *RenderableEntry) {
+	re.Message = r.Redact(re.Message)
+	re.Stacktrace = r.Redact(re.Stacktrace)
+	var joined strings.Builder
+	for i := range re.Segments {
+		re.Segments[i].Text = r.Redact(re.Segments[i].Text)
+		joined.WriteString(re.Segments[i].Text)
+	}
+	if re.Segments != nil && joined.String() != re.Message {
+		re.Segments = nil
+	}
+}
@@ -2,18 +2,20 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"html/template"
 	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"cloud.google.com/go/logging"
-	"cloud.google.com/go/logging/logadmin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
 	"golang.org/x/xerrors"
 	"google.golang.org/api/iterator"
@@ -21,16 +23,33 @@ import (
 
 type Server struct {
 	conf        *config.Config
-	client      *logging.Client
-	adminClient *logadmin.Client
+	backend     Backend
 	entriesTmpl *template.Template
+	indexTmpl   *template.Template
+	index       *buildIndex
+	redactor    *Redactor
 	namespaces  map[string]struct{}
+	// public namespaces can be read without signing in when auth is set.
+	public  map[string]struct{}
+	auth    Authenticator
+	cookies *cookieSigner
+	// stopping is cancelled once the server starts shutting down, ending
+	// any open log streams.
+	stopping context.Context
+	stop     context.CancelFunc
+	ready    atomic.Bool
 }
 
 type EntriesTemplateContext struct {
 	LogsJSON     []RenderableEntry
 	BuildID      string
 	PipelineName string
+	Namespace    string
+	FilterValues map[string]string
+	StreamURL    string
+	NextURL      string
+	PrevURL      string
+	Truncated    bool
 }
 
 type logRequestParams struct {
@@ -48,18 +67,23 @@ const (
 )
 
 var (
-	prowBuildIDPattern = regexp.MustCompile(`[0-9]+`)
-	uuidBuildIDPattern = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+	prowBuildIDPattern = regexp.MustCompile(`^[0-9]+$`)
+	uuidBuildIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 )
 
 // NewServer returns an instance of Server configured with provided params.
-func NewServer(conf *config.Config, client *logging.Client, adminClient *logadmin.Client, templatePath string) *Server {
+// The html templates are read from templateDir.
+func NewServer(conf *config.Config, backend Backend, templateDir string) *Server {
 	s := &Server{
 		conf:        conf,
-		client:      client,
-		adminClient: adminClient,
-		entriesTmpl: template.Must(template.ParseFiles(templatePath)),
+		backend:     backend,
+		entriesTmpl: template.Must(template.ParseFiles(filepath.Join(templateDir, "entries.html"))),
+		indexTmpl:   template.Must(template.ParseFiles(filepath.Join(templateDir, "index.html"))),
+		index:       newBuildIndex(conf.IndexCacheTTL),
+		redactor:    MustNewRedactor(conf.RedactPatterns),
+		public:      namespaceSet(conf.PublicNamespaces),
 	}
+	s.stopping, s.stop = context.WithCancel(context.Background())
 	s.buildNamespaceSet()
 	return s
 }
@@ -67,38 +91,120 @@ func NewServer(conf *config.Config, client *logging.Client, adminClient *logadmi
 // buildNamespaceSet takes the namespaces passed in config and creates
 // a Set to efficiently check whether a given namespace is supported.
 func (s *Server) buildNamespaceSet() {
+	s.namespaces = namespaceSet(s.conf.Namespace)
+}
+
+// namespaceSet returns the set of namespaces in a comma-separated list.
+func namespaceSet(list string) map[string]struct{} {
 	namespaces := make(map[string]struct{})
-	ns := strings.Split(s.conf.Namespace, ",")
+	ns := strings.Split(list, ",")
 	for _, n := range ns {
 		namespace := strings.TrimSpace(n)
 		if namespace != "" {
 			namespaces[namespace] = struct{}{}
 		}
 	}
-	s.namespaces = namespaces
+	return namespaces
+}
+
+// Handler returns the http handler serving logs along with the health
+// and metrics endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/{$}", s.instrument("index", false, s.serveLog))
+	mux.HandleFunc("/stream", s.instrument("stream", true, s.streamLog))
+	mux.HandleFunc("/api/v1/entries", s.instrument("entries", false, s.serveEntriesAPI))
+	mux.HandleFunc("/api/v1/builds", s.instrument("builds", false, s.serveBuildsAPI))
+	mux.HandleFunc("/healthz", s.healthz)
+	mux.HandleFunc("/readyz", s.readyz)
+	mux.Handle("/metrics", promhttp.Handler())
+	if s.auth != nil {
+		mux.HandleFunc("/auth/login", s.instrument("login", false, s.login))
+		mux.HandleFunc("/auth/callback", s.instrument("callback", false, s.callback))
+		mux.HandleFunc("/auth/logout", s.instrument("logout", false, s.logout))
+	}
+	return mux
 }
 
-// Start begins serving logs over http
-func (s *Server) Start() {
-	http.HandleFunc("/", s.serveLog)
+// Run serves logs over http until ctx is done. The server then reports
+// that it is no longer ready, waits for the shutdown delay so that load
+// balancers stop sending it requests, closes open log streams and waits
+// up to the shutdown timeout for requests in flight to finish.
+func (s *Server) Run(ctx context.Context) error {
 	addr := fmt.Sprintf("%s:%s", s.conf.Hostname, s.conf.Port)
-	log.Printf("Serving %s", addr)
-	log.Fatal(http.ListenAndServe(addr, nil))
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return xerrors.Errorf("error listening on %s: %w", addr, err)
+	}
+	return s.serve(ctx, l)
 }
 
-// serveLog serves up an html page with log entries rendered as a JSON object
-// in the head of the document.
-func (s *Server) serveLog(w http.ResponseWriter, r *http.Request) {
-	log.Printf("%s?%s", r.URL.Path, r.URL.RawQuery)
+func (s *Server) serve(ctx context.Context, l net.Listener) error {
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	errc := make(chan error, 1)
+	go func() {
+		errc <- srv.Serve(l)
+	}()
+	log.Printf("Serving %s", l.Addr())
+	s.ready.Store(true)
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down")
+	s.ready.Store(false)
+	select {
+	case err := <-errc:
+		return err
+	case <-time.After(s.conf.ShutdownDelay):
+	}
+	s.stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), s.conf.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return xerrors.Errorf("error shutting down: %w", err)
+	}
+	return nil
+}
+
+// healthz reports that the server is alive.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "ok")
+}
+
+// readyz reports whether the server is accepting requests, which it stops
+// doing once it starts shutting down.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintln(w, "shutting down")
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
 
+// queryForRequest validates the parameters of a request for logs and
+// builds the backend query for them. If the request is invalid an error
+// status is written and ok is false.
+func (s *Server) queryForRequest(w http.ResponseWriter, r *http.Request) (params *logRequestParams, query *Query, ok bool) {
 	params, err := s.getParams(r.URL)
 	if err != nil {
 		log.Printf("disallowing request for logs: %v", err)
 		w.WriteHeader(http.StatusNotFound)
-		return
+		return nil, nil, false
 	}
 
-	query := &Query{
+	if !s.authorize(w, r, params.namespace) {
+		return nil, nil, false
+	}
+
+	query = &Query{
 		Project:   s.conf.Project,
 		Cluster:   s.conf.Cluster,
 		Namespace: params.namespace,
@@ -108,55 +214,85 @@ func (s *Server) serveLog(w http.ResponseWriter, r *http.Request) {
 	if err := query.Validate(); err != nil {
 		log.Printf("%v", err)
 		w.WriteHeader(http.StatusNotFound)
+		return nil, nil, false
+	}
+
+	if query.Filter, err = parseFilter(r.URL); err != nil {
+		log.Printf("%v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return params, query, true
+}
+
+// serveLog serves up an html page with log entries rendered as a JSON object
+// in the head of the document, or the entries as plain text, JSON or NDJSON
+// if the request asks for a machine-readable format. Builds with more than
+// PageSize entries are split into pages linked with next/prev links.
+// Requests without a build id are served the index of recent builds.
+func (s *Server) serveLog(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s?%s", r.URL.Path, r.URL.RawQuery)
+
+	if r.URL.Query().Get("buildid") == "" {
+		s.serveIndex(w, r)
 		return
 	}
 
-	ctx := context.Background()
+	_, query, ok := s.queryForRequest(w, r)
+	if !ok {
+		return
+	}
 
-	entries, err := s.fetchAllEntries(ctx, query)
+	format, err := negotiateFormat(r)
 	if err != nil {
 		log.Printf("%v", err)
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	token, err := parsePageToken(r.URL.Query().Get("page"))
+	if err != nil {
+		log.Printf("%v", err)
+		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	j, err := s.logsToJSON(ctx, entries)
+	p, err := s.fetchPage(r.Context(), query, token)
 	if err != nil {
-		log.Printf("error building json for logs: %v", err)
+		log.Printf("%v", err)
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
 	tc := &EntriesTemplateContext{
-		LogsJSON:     j,
+		LogsJSON:     p.Entries,
 		BuildID:      query.BuildID,
-		PipelineName: getPipelineName(entries),
+		PipelineName: p.PipelineName,
+		Namespace:    query.Namespace,
+		FilterValues: filterValues(r.URL),
+		Truncated:    p.Next != nil,
 	}
-
-	if err := s.entriesTmpl.Execute(w, tc); err != nil {
-		log.Printf("error executing template: %v", err)
+	if p.Next != nil {
+		tc.NextURL = pageURL(r.URL, p.Next)
+	} else {
+		// Only the last page keeps tailing the logs.
+		tc.StreamURL = streamURL(r.URL, p.Cursor)
+	}
+	if p.Prev != nil {
+		tc.PrevURL = pageURL(r.URL, p.Prev)
 	}
-}
 
-// logsToJSON converts a list of logging entries into a format suitable to
-// provide to a frontend.
-func (s *Server) logsToJSON(ctx context.Context, entries []*logging.Entry) ([]RenderableEntry, error) {
-	out := make([]RenderableEntry, 0)
-	for _, entry := range entries {
-		s, err := s.structureEntry(entry)
-		if err != nil {
-			return nil, xerrors.Errorf("error structuring log entry: %w", err)
-		}
-		out = append(out, *s)
+	setPageLinks(w, r.URL, p)
+	if err := s.writeEntries(w, format, isDownload(r), tc); err != nil {
+		log.Printf("error writing %s entries: %v", format, err)
+		errorsTotal.WithLabelValues(ErrorWrite).Inc()
 	}
-	return out, nil
 }
 
-// fetchAllEntries iterates over paginated log entries from stackdriver
+// fetchAllEntries iterates over paginated log entries from the backend
 // and returns the complete list.
 func (s *Server) fetchAllEntries(ctx context.Context, query *Query) ([]*logging.Entry, error) {
-	filter := query.ToFilter()
-	iter := s.adminClient.Entries(ctx, logadmin.Filter(filter))
+	iter := s.backend.Entries(ctx, query)
 	var entries []*logging.Entry
 	var err error
 	var count int
@@ -170,31 +306,36 @@ func (s *Server) fetchAllEntries(ctx context.Context, query *Query) ([]*logging.
 	if err != nil && err != iterator.Done {
 		return nil, xerrors.Errorf("error iterating log entries: %w", err)
 	}
+	if err != iterator.Done {
+		truncations.WithLabelValues(TruncationEntries).Inc()
+	}
 	return entries, nil
 }
 
 // structureEntry takes a logging Entry and extracts the fields necessary
-// to provide to the frontend for rendering.
+// to provide to the frontend for rendering. Secrets are redacted.
 func (s *Server) structureEntry(entry *logging.Entry) (*RenderableEntry, error) {
-	ep, err := parseEntryPayload(entry)
+	re, err := parsePayload(entry)
 	if err != nil {
+		errorsTotal.WithLabelValues(ErrorParse).Inc()
 		return nil, err
 	}
 	entryPrefix := fmt.Sprintf("projects/%s/logs/", s.conf.Project)
-	logName := strings.TrimPrefix(entry.LogName, entryPrefix)
-	return &RenderableEntry{
-		TaskName:      entry.Labels[TektonTaskNameLabel],
-		LogName:       logName,
-		Message:       ep.Fields.Msg.Kind.StringValue,
-		Caller:        ep.Fields.Caller.Kind.StringValue,
-		ContainerName: extractContainerName(entry),
-		TimeStamp:     entry.Timestamp.UTC().Format(time.RFC3339),
-	}, nil
+	re.TaskName = entry.Labels[TektonTaskNameLabel]
+	re.LogName = strings.TrimPrefix(entry.LogName, entryPrefix)
+	re.ContainerName = extractContainerName(entry)
+	re.TimeStamp = entry.Timestamp.UTC().Format(time.RFC3339)
+	s.redactor.RedactEntry(re)
+	return re, nil
 }
 
 // validateBuildID confirms that a build id string matches either uuid or
-// a prow formatted build id.
+// a prow formatted build id. Build ids are used in file paths, so they must
+// not contain path separators.
 func (s *Server) validateBuildID(buildID string) error {
+	if strings.ContainsAny(buildID, `/\`) {
+		return fmt.Errorf("build id contains a path separator: %q", buildID)
+	}
 	if uuidBuildIDPattern.MatchString(buildID) || prowBuildIDPattern.MatchString(buildID) {
 		return nil
 	}
@@ -222,43 +363,11 @@ func (s *Server) getParams(url *url.URL) (*logRequestParams, error) {
 	return params, nil
 }
 
-// parseEntryPayload takes a stackdriver logging entry and parses out the payload
-// fields we're interested in returning to the user.
-func parseEntryPayload(entry *logging.Entry) (*EntryPayload, error) {
-	content, isString := entry.Payload.(string)
-	var ep EntryPayload
-	if isString {
-		if err := json.Unmarshal([]byte(content), &ep); err != nil {
-			// payload is just a vanilla log string with no json content
-			ep.Fields.Msg.Kind.StringValue = content
-		}
-	} else {
-		if contentBytes, err := json.Marshal(entry.Payload); err != nil {
-			return nil, err
-		} else if err := json.Unmarshal(contentBytes, &ep); err != nil {
-			return nil, err
-		}
-	}
-	return &ep, nil
-}
-
 // extractContainerName returns the container name from the labels of the
 // stackdriver resource if one is available or an empty string.
 func extractContainerName(entry *logging.Entry) string {
-	if entry.Resource.Type == StackdriverContainerResourceType {
+	if entry.Resource != nil && entry.Resource.Type == StackdriverContainerResourceType {
 		return entry.Resource.GetLabels()[StackdriverContainerNameLabel]
 	}
 	return ""
 }
-
-// getPipelineName fetches the pipeline name from a list of logging entries if any of them
-// provide it in their labels.
-func getPipelineName(entries []*logging.Entry) string {
-	for _, e := range entries {
-		p := e.Labels[TektonPipelineNameLabel]
-		if p != "" {
-			return p
-		}
-	}
-	return ""
-}
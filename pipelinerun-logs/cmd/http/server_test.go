@@ -1,10 +1,17 @@
 package main
 
 import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+	"google.golang.org/api/iterator"
 )
 
 func TestValidateBuildID(t *testing.T) {
@@ -23,6 +30,15 @@ func TestValidateBuildID(t *testing.T) {
 	}, {
 		buildID:   "123456",
 		shouldErr: false,
+	}, {
+		buildID:   "../other-namespace/12345",
+		shouldErr: true,
+	}, {
+		buildID:   "../../../etc/x1",
+		shouldErr: true,
+	}, {
+		buildID:   "12345abc",
+		shouldErr: true,
 	}} {
 		conf := &config.Config{}
 		s := &Server{
@@ -86,3 +102,187 @@ func TestGetParams(t *testing.T) {
 		})
 	}
 }
+
+func TestServeLog(t *testing.T) {
+	conf := &config.Config{Project: "FooProject", Namespace: "default", PageSize: 10000}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	for _, tc := range []struct {
+		description    string
+		url            string
+		expectedStatus int
+		expectedBody   []string
+	}{{
+		description:    "renders entries from the backend in timestamp order",
+		url:            "/?namespace=default&buildid=12345",
+		expectedStatus: http.StatusOK,
+		expectedBody: []string{
+			`(Pipeline "plumbing-ci")`,
+			`"msg":"Cloning into 'plumbing'..."`,
+			`"log":"stderr","msg":"unit tests finished","caller":"main.go:42"`,
+		},
+	}, {
+		description:    "renders an empty page for a build without logs",
+		url:            "/?namespace=default&buildid=99999",
+		expectedStatus: http.StatusOK,
+		expectedBody:   []string{"const logEntries = [];"},
+	}, {
+		description:    "filters entries by level",
+		url:            "/?namespace=default&buildid=12345&level=warn",
+		expectedStatus: http.StatusOK,
+		expectedBody: []string{
+			`"msg":"unit tests finished","caller":"main.go:42","container":"step-unit-test","ts":"2020-01-02T15:04:07Z","level":"error"`,
+		},
+	}, {
+		description:    "filters entries by task and search expression",
+		url:            "/?namespace=default&buildid=12345&task=unit-tests&q=%5Eok",
+		expectedStatus: http.StatusOK,
+		expectedBody:   []string{`"msg":"ok  \tgithub.com`, `value="unit-tests"`},
+	}, {
+		description:    "rejects invalid search expressions",
+		url:            "/?namespace=default&buildid=12345&q=%28",
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		description:    "rejects build ids escaping the namespace",
+		url:            "/?namespace=default&buildid=../kube-system/12345",
+		expectedStatus: http.StatusNotFound,
+	}, {
+		description:    "disallows unsupported namespaces",
+		url:            "/?namespace=kube-system&buildid=12345",
+		expectedStatus: http.StatusNotFound,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			s.serveLog(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+			body := w.Body.String()
+			last := -1
+			for _, expected := range tc.expectedBody {
+				i := strings.Index(body, expected)
+				if i < 0 {
+					t.Fatalf("expected body to contain %s", expected)
+				}
+				if i < last {
+					t.Errorf("expected %s to be rendered after previous entries", expected)
+				}
+				last = i
+			}
+		})
+	}
+}
+
+func TestFileBackendRejectsEscapingBuildIDs(t *testing.T) {
+	b := NewFileBackend("testdata/logs")
+	for _, buildID := range []string{"../12345", "../../testdata/logs/default/12345", "", "."} {
+		it := b.Entries(context.Background(), &Query{Namespace: "default", BuildID: buildID})
+		if _, err := it.Next(); err == nil || err == iterator.Done {
+			t.Errorf("expected build id %q to be rejected but received %v", buildID, err)
+		}
+	}
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	conf := &config.Config{Namespace: "default"}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+	h := s.Handler()
+
+	for _, tc := range []struct {
+		description    string
+		url            string
+		ready          bool
+		expectedStatus int
+	}{{
+		description:    "is always healthy",
+		url:            "/healthz",
+		expectedStatus: http.StatusOK,
+	}, {
+		description:    "is not ready before serving",
+		url:            "/readyz",
+		expectedStatus: http.StatusServiceUnavailable,
+	}, {
+		description:    "is ready while serving",
+		url:            "/readyz",
+		ready:          true,
+		expectedStatus: http.StatusOK,
+	}, {
+		description:    "serves metrics",
+		url:            "/metrics",
+		expectedStatus: http.StatusOK,
+	}, {
+		description:    "does not serve the index at other paths",
+		url:            "/favicon.ico?namespace=default&buildid=12345",
+		expectedStatus: http.StatusNotFound,
+	}, {
+		description:    "serves the index at the root",
+		url:            "/?namespace=default&buildid=12345",
+		expectedStatus: http.StatusOK,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			s.ready.Store(tc.ready)
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			if w.Code != tc.expectedStatus {
+				t.Errorf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestServeGracefulShutdown(t *testing.T) {
+	conf := &config.Config{
+		Namespace:          "default",
+		ShutdownDelay:      50 * time.Millisecond,
+		ShutdownTimeout:    time.Second,
+		StreamPollInterval: 10 * time.Millisecond,
+		StreamIdleTimeout:  time.Hour,
+	}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	base := "http://" + l.Addr().String()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() {
+		errc <- s.serve(ctx, l)
+	}()
+	waitForStatus(t, base+"/readyz", http.StatusOK)
+
+	// an open stream must not hold up the shutdown
+	resp, err := http.Get(base + "/stream?namespace=default&buildid=12345")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	cancel()
+	waitForStatus(t, base+"/readyz", http.StatusServiceUnavailable)
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
+
+// waitForStatus polls url until it responds with the expected status.
+func waitForStatus(t *testing.T, url string, expected int) {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		resp, err := http.Get(url)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == expected {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s did not respond with status %d", url, expected)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"cloud.google.com/go/logging/logadmin"
+	"golang.org/x/xerrors"
+)
+
+// StackdriverBackend reads log entries from Google Cloud Logging.
+type StackdriverBackend struct {
+	adminClient *logadmin.Client
+}
+
+// NewStackdriverBackend returns a StackdriverBackend reading logs from the
+// given project using application default credentials.
+func NewStackdriverBackend(ctx context.Context, project string) (*StackdriverBackend, error) {
+	adminClient, err := logadmin.NewClient(ctx, project)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to create adminClient: %w", err)
+	}
+	return &StackdriverBackend{adminClient: adminClient}, nil
+}
+
+// Entries runs the query's stackdriver filter against Cloud Logging.
+func (b *StackdriverBackend) Entries(ctx context.Context, query *Query) EntryIterator {
+	return b.adminClient.Entries(ctx, logadmin.Filter(query.ToFilter()))
+}
+
+// Builds runs the index query's stackdriver filter against Cloud Logging,
+// newest entries first.
+func (b *StackdriverBackend) Builds(ctx context.Context, query *IndexQuery) EntryIterator {
+	return b.adminClient.Entries(ctx, logadmin.Filter(query.ToFilter()), logadmin.NewestFirst())
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"golang.org/x/xerrors"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	StreamEntryEvent = "entry"
+	StreamEndEvent   = "end"
+	// StreamFlushSize is the number of entry events written between flushes
+	// while entries are read from the backend.
+	StreamFlushSize = 100
+)
+
+// streamCursor tracks the position of a client in a build's logs. Backends
+// are queried from the last seen timestamp inclusively so that entries
+// arriving late with the same timestamp are not lost, and the insert ids
+// already seen at that timestamp are used to drop duplicates.
+type streamCursor struct {
+	ts   time.Time
+	seen map[string]struct{}
+	ids  []string
+}
+
+// parseStreamCursor reads a cursor in the form "<RFC3339Nano>" or
+// "<RFC3339Nano>/<insertId>[,<insertId>...]". An empty string starts from
+// the beginning of the build's logs.
+func parseStreamCursor(s string) (*streamCursor, error) {
+	c := &streamCursor{seen: make(map[string]struct{})}
+	if s == "" {
+		return c, nil
+	}
+	ts, insertIDs := s, ""
+	if i := strings.Index(s, "/"); i >= 0 {
+		ts, insertIDs = s[:i], s[i+1:]
+	}
+	t, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid stream cursor %q: %w", s, err)
+	}
+	c.ts = t
+	if insertIDs != "" {
+		for _, id := range strings.Split(insertIDs, ",") {
+			c.markSeen(id)
+		}
+	}
+	return c, nil
+}
+
+// String returns the cursor in the format accepted by parseStreamCursor.
+func (c *streamCursor) String() string {
+	if c.ts.IsZero() {
+		return ""
+	}
+	s := c.ts.UTC().Format(time.RFC3339Nano)
+	if len(c.ids) > 0 {
+		s += "/" + strings.Join(c.ids, ",")
+	}
+	return s
+}
+
+func (c *streamCursor) markSeen(id string) {
+	if _, ok := c.seen[id]; !ok {
+		c.seen[id] = struct{}{}
+		c.ids = append(c.ids, id)
+	}
+}
+
+// seenEntry reports whether the entry is at or before the cursor.
+func (c *streamCursor) seenEntry(e *logging.Entry) bool {
+	if e.Timestamp.Before(c.ts) {
+		return true
+	}
+	if e.Timestamp.After(c.ts) {
+		return false
+	}
+	_, ok := c.seen[e.InsertID]
+	return ok
+}
+
+// add moves the cursor past the entry. It returns false if the entry had
+// already been seen.
+func (c *streamCursor) add(e *logging.Entry) bool {
+	if c.seenEntry(e) {
+		return false
+	}
+	if e.Timestamp.After(c.ts) {
+		c.ts = e.Timestamp
+		c.seen = make(map[string]struct{})
+		c.ids = nil
+	}
+	c.markSeen(e.InsertID)
+	return true
+}
+
+// advance returns the entries that have not been seen yet and moves the
+// cursor past them. Entries must be ordered by timestamp.
+func (c *streamCursor) advance(entries []*logging.Entry) []*logging.Entry {
+	var out []*logging.Entry
+	for _, e := range entries {
+		if c.add(e) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// streamURL returns the url a client can use to tail the logs of a build
+// after the given cursor, keeping the build and filter parameters of the
+// request u.
+func streamURL(u *url.URL, cursor string) string {
+	q := u.Query()
+	v := url.Values{}
+	for _, p := range append([]string{"buildid", "namespace"}, FilterParams...) {
+		if q.Get(p) != "" {
+			v.Set(p, q.Get(p))
+		}
+	}
+	if cursor != "" {
+		v.Set("since", cursor)
+	}
+	return "/stream?" + v.Encode()
+}
+
+// streamLog tails the logs of a build as server-sent events. New entries
+// are sent as "entry" events containing a RenderableEntry. Once no new
+// entries have been seen for the configured idle timeout the build is
+// considered finished and an "end" event closes the stream.
+func (s *Server) streamLog(w http.ResponseWriter, r *http.Request) {
+	log.Printf("%s?%s", r.URL.Path, r.URL.RawQuery)
+
+	_, query, ok := s.queryForRequest(w, r)
+	if !ok {
+		return
+	}
+
+	// EventSource sends the id of the last event it received when it
+	// reconnects, which takes precedence over the initial cursor.
+	since := r.Header.Get("Last-Event-ID")
+	if since == "" {
+		since = r.URL.Query().Get("since")
+	}
+	cursor, err := parseStreamCursor(since)
+	if err != nil {
+		log.Printf("%v", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		log.Printf("streaming unsupported by response writer")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	ticker := time.NewTicker(s.conf.StreamPollInterval)
+	defer ticker.Stop()
+	lastActivity := time.Now()
+	for {
+		query.Since = cursor.ts
+		sent, err := s.streamEntries(ctx, w, flusher, query, cursor)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("%v", err)
+			}
+			return
+		}
+		if sent > 0 {
+			lastActivity = time.Now()
+			flusher.Flush()
+		}
+
+		if time.Since(lastActivity) >= s.conf.StreamIdleTimeout {
+			if err := writeEvent(w, StreamEndEvent, cursor.String(), struct{}{}); err != nil {
+				log.Printf("error writing stream event: %v", err)
+				errorsTotal.WithLabelValues(ErrorWrite).Inc()
+			}
+			flusher.Flush()
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// streamEntries sends the entries of the query that are past the cursor as
+// they are read from the backend, instead of buffering them, and moves the
+// cursor past them. It returns the number of entry events sent.
+func (s *Server) streamEntries(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, query *Query, cursor *streamCursor) (int, error) {
+	iter := s.backend.Entries(ctx, query)
+	var sent int
+	for count := 0; count < MaxFetchedLogEntries; count++ {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			return sent, nil
+		}
+		if err != nil {
+			return sent, xerrors.Errorf("error iterating log entries: %w", err)
+		}
+		if !cursor.add(entry) {
+			continue
+		}
+		re, err := s.structureEntry(entry)
+		if err != nil {
+			return sent, xerrors.Errorf("error structuring log entry: %w", err)
+		}
+		if !query.Filter.Matches(entry, re) {
+			continue
+		}
+		if err := writeEvent(w, StreamEntryEvent, cursor.String(), re); err != nil {
+			errorsTotal.WithLabelValues(ErrorWrite).Inc()
+			return sent, xerrors.Errorf("error writing stream event: %w", err)
+		}
+		sent++
+		if sent%StreamFlushSize == 0 {
+			flusher.Flush()
+		}
+	}
+	truncations.WithLabelValues(TruncationEntries).Inc()
+	return sent, nil
+}
+
+// writeEvent writes a single server-sent event with a JSON encoded payload.
+func writeEvent(w http.ResponseWriter, event, id string, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, b)
+	return err
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"github.com/tektoncd/plumbing/pipelinerun-logs/pkg/config"
+)
+
+func TestStreamCursor(t *testing.T) {
+	t0 := time.Date(2020, 1, 2, 15, 4, 5, 0, time.UTC)
+	t1 := t0.Add(time.Second)
+	c, err := parseStreamCursor("2020-01-02T15:04:05Z/a")
+	if err != nil {
+		t.Fatalf("unexpected error parsing cursor: %v", err)
+	}
+
+	got := c.advance([]*logging.Entry{
+		{Timestamp: t0.Add(-time.Second), InsertID: "z"},
+		{Timestamp: t0, InsertID: "a"},
+		{Timestamp: t0, InsertID: "b"},
+		{Timestamp: t1, InsertID: "c"},
+	})
+	if len(got) != 2 || got[0].InsertID != "b" || got[1].InsertID != "c" {
+		t.Errorf("expected entries b and c to be new but received %v", got)
+	}
+	if c.String() != "2020-01-02T15:04:06Z/c" {
+		t.Errorf("unexpected cursor %q", c.String())
+	}
+
+	got = c.advance([]*logging.Entry{
+		{Timestamp: t1, InsertID: "c"},
+		{Timestamp: t1, InsertID: "d"},
+	})
+	if len(got) != 1 || got[0].InsertID != "d" {
+		t.Errorf("expected only entry d to be new but received %v", got)
+	}
+
+	if _, err := parseStreamCursor("yesterday"); err == nil {
+		t.Error("expected error parsing invalid cursor")
+	}
+}
+
+func TestStreamLog(t *testing.T) {
+	conf := &config.Config{
+		Project:            "FooProject",
+		Namespace:          "default",
+		StreamPollInterval: 10 * time.Millisecond,
+		StreamIdleTimeout:  50 * time.Millisecond,
+	}
+	s := NewServer(conf, NewFileBackend("testdata/logs"), "../../templates")
+
+	for _, tc := range []struct {
+		description     string
+		url             string
+		lastEventID     string
+		expectedStatus  int
+		expectedEntries []string
+	}{{
+		description:     "streams all entries then ends",
+		url:             "/stream?namespace=default&buildid=12345",
+		expectedStatus:  http.StatusOK,
+		expectedEntries: []string{"Cloning into", "ok  ", "unit tests finished"},
+	}, {
+		description:     "streams entries after the since cursor",
+		url:             "/stream?namespace=default&buildid=12345&since=2020-01-02T15:04:05Z/unit-tests.json:1",
+		expectedStatus:  http.StatusOK,
+		expectedEntries: []string{"unit tests finished"},
+	}, {
+		description:     "resumes from the last event id",
+		url:             "/stream?namespace=default&buildid=12345",
+		lastEventID:     "2020-01-02T15:04:00Z/git-clone.json:1",
+		expectedStatus:  http.StatusOK,
+		expectedEntries: []string{"ok  ", "unit tests finished"},
+	}, {
+		description:    "rejects invalid cursors",
+		url:            "/stream?namespace=default&buildid=12345&since=yesterday",
+		expectedStatus: http.StatusBadRequest,
+	}, {
+		description:    "disallows unsupported namespaces",
+		url:            "/stream?namespace=kube-system&buildid=12345",
+		expectedStatus: http.StatusNotFound,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.lastEventID != "" {
+				r.Header.Set("Last-Event-ID", tc.lastEventID)
+			}
+			w := httptest.NewRecorder()
+			s.streamLog(w, r)
+			if w.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d received %d", tc.expectedStatus, w.Code)
+			}
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+
+			events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+			if len(events) != len(tc.expectedEntries)+1 {
+				t.Fatalf("expected %d entry events and an end event but received %q", len(tc.expectedEntries), events)
+			}
+			for i, expected := range tc.expectedEntries {
+				if !strings.Contains(events[i], "event: entry\n") || !strings.Contains(events[i], expected) {
+					t.Errorf("expected entry event containing %q but received %q", expected, events[i])
+				}
+			}
+			if end := events[len(events)-1]; !strings.Contains(end, "event: end\n") {
+				t.Errorf("expected stream to finish with an end event but received %q", end)
+			}
+		})
+	}
+}
@@ -3,6 +3,22 @@ package config
 import (
 	"errors"
 	"flag"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	BackendStackdriver = "stackdriver"
+	BackendLoki        = "loki"
+	BackendFile        = "file"
+
+	CacheMemory = "memory"
+	CacheDisk   = "disk"
+
+	AuthOIDC   = "oidc"
+	AuthGitHub = "github"
 )
 
 type Config struct {
@@ -11,6 +27,51 @@ type Config struct {
 	Project   string
 	Cluster   string
 	Namespace string
+	Backend   string
+	LokiURL   string
+	LogDir    string
+	PageSize  int
+
+	RequestTimeout  time.Duration
+	ShutdownDelay   time.Duration
+	ShutdownTimeout time.Duration
+
+	StreamPollInterval time.Duration
+	StreamIdleTimeout  time.Duration
+
+	IndexLookback time.Duration
+	IndexCacheTTL time.Duration
+
+	Cache     string
+	CacheDir  string
+	CacheSize int
+	CacheTTL  time.Duration
+
+	// RedactPatterns are regular expressions matching secrets to hide in
+	// addition to the built-in ones.
+	RedactPatterns []string
+
+	Auth                  string
+	PublicNamespaces      string
+	OIDCIssuerURL         string
+	OIDCEmailDomain       string
+	GitHubOrg             string
+	OAuthClientID         string
+	OAuthClientSecretFile string
+	OAuthRedirectURL      string
+	SessionSecretFile     string
+}
+
+// stringsFlag is a flag.Value collecting every value a flag is given.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *stringsFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
 }
 
 func (c *Config) ParseFlags() {
@@ -19,6 +80,31 @@ func (c *Config) ParseFlags() {
 	flag.StringVar(&c.Namespace, "namespace", "", "comma-separated list of namespace names to allow queries against for logs")
 	flag.StringVar(&c.Hostname, "hostname", "localhost", "hostname to bind to")
 	flag.StringVar(&c.Port, "port", "9999", "port to bind to")
+	flag.StringVar(&c.Backend, "backend", BackendStackdriver, "log backend to read from: stackdriver, loki or file")
+	flag.StringVar(&c.LokiURL, "loki-url", "", "base url of the loki instance to query when using the loki backend")
+	flag.StringVar(&c.LogDir, "log-dir", "", "directory to read logs from when using the file backend")
+	flag.IntVar(&c.PageSize, "page-size", 10000, "maximum number of log entries served per page")
+	flag.DurationVar(&c.RequestTimeout, "request-timeout", time.Minute, "maximum time spent serving a request, log streams excepted")
+	flag.DurationVar(&c.ShutdownDelay, "shutdown-delay", 5*time.Second, "how long the server keeps serving requests after reporting it is not ready when shutting down")
+	flag.DurationVar(&c.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "how long requests in flight are given to finish when shutting down")
+	flag.DurationVar(&c.StreamPollInterval, "stream-poll-interval", 5*time.Second, "how often the backend is polled for new entries when streaming logs")
+	flag.DurationVar(&c.StreamIdleTimeout, "stream-idle-timeout", 10*time.Minute, "how long a log stream stays open without new entries before it is considered finished")
+	flag.DurationVar(&c.IndexLookback, "index-lookback", 24*time.Hour, "how far back the index of recent builds reaches")
+	flag.DurationVar(&c.IndexCacheTTL, "index-cache-ttl", time.Minute, "how long the index of recent builds is cached for")
+	flag.StringVar(&c.Cache, "cache", "", "cache for build logs: memory, disk or empty to disable caching")
+	flag.StringVar(&c.CacheDir, "cache-dir", "", "directory to store cached build logs in when using the disk cache")
+	flag.IntVar(&c.CacheSize, "cache-size", 100, "maximum number of builds held by the cache")
+	flag.DurationVar(&c.CacheTTL, "cache-ttl", 15*time.Second, "how long the logs of builds still in progress are cached for")
+	flag.Var((*stringsFlag)(&c.RedactPatterns), "redact", "regular expression matching secrets to redact from logs, may be repeated")
+	flag.StringVar(&c.Auth, "auth", "", "authentication required for non-public namespaces: oidc, github or empty to make all namespaces public")
+	flag.StringVar(&c.PublicNamespaces, "public-namespaces", "", "comma-separated list of namespaces readable without signing in when authentication is enabled")
+	flag.StringVar(&c.OIDCIssuerURL, "oidc-issuer-url", "", "issuer url of the OpenID Connect provider when using oidc authentication")
+	flag.StringVar(&c.OIDCEmailDomain, "oidc-email-domain", "", "if set, only users with a verified email address in this domain are allowed to sign in with oidc")
+	flag.StringVar(&c.GitHubOrg, "github-org", "", "GitHub organization users must be members of when using github authentication")
+	flag.StringVar(&c.OAuthClientID, "oauth-client-id", "", "OAuth client id registered with the identity provider")
+	flag.StringVar(&c.OAuthClientSecretFile, "oauth-client-secret-file", "", "file containing the OAuth client secret")
+	flag.StringVar(&c.OAuthRedirectURL, "oauth-redirect-url", "", "public url of the /auth/callback endpoint of this app")
+	flag.StringVar(&c.SessionSecretFile, "session-secret-file", "", "file containing the key used to sign session cookies")
 	flag.Parse()
 }
 
@@ -31,8 +117,81 @@ func (c *Config) Validate() error {
 		return errors.New("missing port")
 	}
 
-	if c.Project == "" || c.Cluster == "" || c.Namespace == "" {
-		return errors.New("missed configuration: project, cluster, namespace")
+	if c.Namespace == "" {
+		return errors.New("missed configuration: namespace")
+	}
+
+	switch c.Backend {
+	case "", BackendStackdriver:
+		if c.Project == "" || c.Cluster == "" {
+			return errors.New("missed configuration for stackdriver backend: project, cluster")
+		}
+	case BackendLoki:
+		if c.LokiURL == "" {
+			return errors.New("missed configuration for loki backend: loki-url")
+		}
+	case BackendFile:
+		if c.LogDir == "" {
+			return errors.New("missed configuration for file backend: log-dir")
+		}
+	default:
+		return fmt.Errorf("unknown backend %q", c.Backend)
+	}
+
+	if c.PageSize <= 0 {
+		return errors.New("invalid configuration: page-size must be positive")
+	}
+
+	if c.RequestTimeout < 0 || c.ShutdownDelay < 0 || c.ShutdownTimeout < 0 {
+		return errors.New("invalid configuration: request-timeout, shutdown-delay and shutdown-timeout must not be negative")
+	}
+
+	if c.StreamPollInterval <= 0 || c.StreamIdleTimeout <= 0 {
+		return errors.New("invalid configuration: stream-poll-interval and stream-idle-timeout must be positive")
+	}
+
+	if c.IndexLookback <= 0 {
+		return errors.New("invalid configuration: index-lookback must be positive")
+	}
+
+	switch c.Cache {
+	case "":
+	case CacheMemory:
+		if c.CacheSize <= 0 {
+			return errors.New("invalid configuration: cache-size must be positive")
+		}
+	case CacheDisk:
+		if c.CacheDir == "" {
+			return errors.New("missed configuration for disk cache: cache-dir")
+		}
+		if c.CacheSize <= 0 {
+			return errors.New("invalid configuration: cache-size must be positive")
+		}
+	default:
+		return fmt.Errorf("unknown cache %q", c.Cache)
+	}
+
+	for _, p := range c.RedactPatterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid redact pattern %q: %v", p, err)
+		}
+	}
+
+	switch c.Auth {
+	case "":
+	case AuthOIDC:
+		if c.OIDCIssuerURL == "" {
+			return errors.New("missed configuration for oidc authentication: oidc-issuer-url")
+		}
+	case AuthGitHub:
+		if c.GitHubOrg == "" {
+			return errors.New("missed configuration for github authentication: github-org")
+		}
+	default:
+		return fmt.Errorf("unknown authentication %q", c.Auth)
+	}
+	if c.Auth != "" && (c.OAuthClientID == "" || c.OAuthClientSecretFile == "" || c.OAuthRedirectURL == "" || c.SessionSecretFile == "") {
+		return errors.New("missed configuration for authentication: oauth-client-id, oauth-client-secret-file, oauth-redirect-url, session-secret-file")
 	}
 	return nil
 }
@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -27,6 +28,178 @@ func TestValidate(t *testing.T) {
 			Namespace: "FooNamespace",
 		},
 		expectedError: "cluster",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			Backend:   BackendStackdriver,
+		},
+		expectedError: "project",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Namespace: "FooNamespace",
+			Backend:   BackendLoki,
+		},
+		expectedError: "loki-url",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Namespace: "FooNamespace",
+			Backend:   BackendFile,
+		},
+		expectedError: "log-dir",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Namespace: "FooNamespace",
+			Backend:   "elasticsearch",
+		},
+		expectedError: "unknown backend",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+		},
+		expectedError: "page-size",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+		},
+		expectedError: "stream-poll-interval",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+		},
+		expectedError: "index-lookback",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+			Cache:     CacheDisk,
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "cache-dir",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+			Cache:     "redis",
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "unknown cache",
+	}, {
+		c: &Config{
+			Hostname:       "localhost",
+			Port:           "9999",
+			Project:        "FooProject",
+			Cluster:        "FooCluster",
+			Namespace:      "FooNamespace",
+			PageSize:       10000,
+			RequestTimeout: -time.Second,
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "request-timeout",
+	}, {
+		c: &Config{
+			Hostname:       "localhost",
+			Port:           "9999",
+			Project:        "FooProject",
+			Cluster:        "FooCluster",
+			Namespace:      "FooNamespace",
+			PageSize:       10000,
+			RedactPatterns: []string{"("},
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "invalid redact pattern",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+			Auth:      "saml",
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "unknown authentication",
+	}, {
+		c: &Config{
+			Hostname:  "localhost",
+			Port:      "9999",
+			Project:   "FooProject",
+			Cluster:   "FooCluster",
+			Namespace: "FooNamespace",
+			PageSize:  10000,
+			Auth:      AuthGitHub,
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "github-org",
+	}, {
+		c: &Config{
+			Hostname:      "localhost",
+			Port:          "9999",
+			Project:       "FooProject",
+			Cluster:       "FooCluster",
+			Namespace:     "FooNamespace",
+			PageSize:      10000,
+			Auth:          AuthOIDC,
+			OIDCIssuerURL: "https://accounts.google.com",
+
+			StreamPollInterval: time.Second,
+			StreamIdleTimeout:  time.Minute,
+			IndexLookback:      time.Hour,
+		},
+		expectedError: "session-secret-file",
 	}} {
 		err := tc.c.Validate()
 		if err == nil || !strings.Contains(err.Error(), tc.expectedError) {
@@ -34,3 +207,33 @@ func TestValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateNonStackdriverBackends(t *testing.T) {
+	for _, c := range []*Config{{
+		Hostname:  "localhost",
+		Port:      "9999",
+		Namespace: "FooNamespace",
+		Backend:   BackendLoki,
+		LokiURL:   "http://loki:3100",
+		PageSize:  10000,
+
+		StreamPollInterval: time.Second,
+		StreamIdleTimeout:  time.Minute,
+		IndexLookback:      time.Hour,
+	}, {
+		Hostname:  "localhost",
+		Port:      "9999",
+		Namespace: "FooNamespace",
+		Backend:   BackendFile,
+		LogDir:    "/var/log/pipelineruns",
+		PageSize:  10000,
+
+		StreamPollInterval: time.Second,
+		StreamIdleTimeout:  time.Minute,
+		IndexLookback:      time.Hour,
+	}} {
+		if err := c.Validate(); err != nil {
+			t.Errorf("expected %s backend config to be valid but received %v", c.Backend, err)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+// Command dryrun validates github interceptor configs, and shows how a
+// config handles a recorded webhook event without GitHub or a cluster.
+//
+//	dryrun validate CONFIG...
+//	dryrun run -config CONFIG -payload PAYLOAD [-headers FILE] [-H "Key: value"]... [-fixtures DIR]
+//
+// Configs are the value of the interceptor's "config" param, in YAML or JSON.
+// run exits with status 1 if the event would not continue.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"sigs.k8s.io/yaml"
+)
+
+const usage = `usage:
+  dryrun validate CONFIG...
+  dryrun run -config CONFIG -payload PAYLOAD [-headers FILE] [-H "Key: value"]... [-fixtures DIR]
+`
+
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	switch os.Args[1] {
+	case "validate":
+		validate(os.Args[2:])
+	case "run":
+		run(os.Args[2:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func validate(paths []string) {
+	if len(paths) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	failed := false
+	for _, path := range paths {
+		if _, err := readConfig(path); err != nil {
+			fmt.Printf("%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("%s: ok\n", path)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// headerFlags collects repeated -H flags.
+type headerFlags []string
+
+func (h *headerFlags) String() string     { return strings.Join(*h, ", ") }
+func (h *headerFlags) Set(v string) error { *h = append(*h, v); return nil }
+
+func run(args []string) {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to the interceptor config, in YAML or JSON")
+	payloadPath := fs.String("payload", "", "path to the webhook payload")
+	headersPath := fs.String("headers", "", "path to the webhook headers, one \"Key: value\" per line as shown in GitHub's recent deliveries")
+	event := fs.String("event", "", "webhook event type, shorthand for -H \"X-GitHub-Event: EVENT\"")
+	fixtures := fs.String("fixtures", "", "directory of GitHub API responses served by the fake GitHub backend")
+	secretPath := fs.String("webhook_secret_path", "", "path to the webhook secret; if unset, signatures are not checked")
+	var headers headerFlags
+	fs.Var(&headers, "H", "webhook header, as \"Key: value\" (repeatable)")
+	_ = fs.Parse(args)
+	if *configPath == "" || *payloadPath == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	params, err := readConfig(*configPath)
+	if err != nil {
+		log.Fatalf("%s: %v", *configPath, err)
+	}
+	payload, err := os.ReadFile(*payloadPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	h := http.Header{}
+	if *headersPath != "" {
+		b, err := os.ReadFile(*headersPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := parseHeaders(h, strings.Split(string(b), "\n")); err != nil {
+			log.Fatalf("%s: %v", *headersPath, err)
+		}
+	}
+	if err := parseHeaders(h, headers); err != nil {
+		log.Fatal(err)
+	}
+	if *event != "" {
+		h.Set("X-GitHub-Event", *event)
+	}
+	if h.Get("X-GitHub-Event") == "" {
+		log.Fatal("no X-GitHub-Event header, set -event or -H")
+	}
+
+	var secret []byte
+	if *secretPath != "" {
+		if secret, err = os.ReadFile(*secretPath); err != nil {
+			log.Fatal(err)
+		}
+		secret = bytes.TrimSpace(secret)
+	} else {
+		h.Del("X-Hub-Signature-256")
+	}
+
+	gh := httptest.NewServer(fixtureHandler(*fixtures))
+	defer gh.Close()
+	baseURL, err := url.Parse(gh.URL + "/")
+	if err != nil {
+		log.Fatal(err)
+	}
+	s := github.New(gh.Client(), secret, github.WithBaseURL(baseURL))
+
+	b, err := json.Marshal(&v1alpha1.InterceptorRequest{
+		Body:              string(payload),
+		Header:            h,
+		InterceptorParams: map[string]interface{}{"config": params},
+		Context:           &v1alpha1.TriggerContext{TriggerID: "dryrun"},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	rw := httptest.NewRecorder()
+	s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(b)))
+
+	resp := new(v1alpha1.InterceptorResponse)
+	if err := json.Unmarshal(rw.Body.Bytes(), resp); err != nil {
+		log.Fatalf("error reading response %q: %v", rw.Body.String(), err)
+	}
+	out, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(string(out))
+	if !resp.Continue {
+		fmt.Printf("\ndoes not continue: %s: %s\n", resp.Status.Code, resp.Status.Message)
+		os.Exit(1)
+	}
+	fmt.Println("\ncontinues")
+}
+
+// readConfig reads and validates a config, and returns it as interceptor
+// params.
+func readConfig(path string) (interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	j, err := yaml.YAMLToJSON(b)
+	if err != nil {
+		return nil, err
+	}
+	cfg := new(pb.Config)
+	if err := protojson.Unmarshal(j, cfg); err != nil {
+		return nil, err
+	}
+	if err := github.Validate(cfg); err != nil {
+		return nil, err
+	}
+	var params interface{}
+	if err := json.Unmarshal(j, &params); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// parseHeaders adds "Key: value" lines to h. Blank lines are skipped.
+func parseHeaders(h http.Header, lines []string) error {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			return fmt.Errorf("invalid header %q, want \"Key: value\"", line)
+		}
+		h.Add(strings.TrimSpace(k), strings.TrimSpace(v))
+	}
+	return nil
+}
+
+// fixtureHandler fakes the GitHub API with responses read from dir. The
+// response to e.g. GET /repos/tektoncd/plumbing/pulls/1 is read from
+// dir/repos/tektoncd/plumbing/pulls/1.json. Files under
+// dir/repos/OWNER/REPO/contents/ without a .json extension are served as
+// repository file contents, so that OWNERS files can be copied as is.
+// Requests without a fixture get a 404, and are logged so that the missing
+// fixtures can be added.
+func fixtureHandler(dir string) http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if dir != "" && !strings.Contains(r.URL.Path, "..") {
+			path := filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(r.URL.Path, "/")))
+			if b, err := os.ReadFile(path + ".json"); err == nil {
+				log.Printf("github: %s %s: served %s.json", r.Method, r.URL.Path, path)
+				rw.Header().Set("Content-Type", "application/json")
+				_, _ = rw.Write(b)
+				return
+			}
+			if parts := strings.SplitN(r.URL.Path, "/", 6); len(parts) == 6 && parts[1] == "repos" && parts[4] == "contents" {
+				if b, err := os.ReadFile(path); err == nil {
+					log.Printf("github: %s %s: served %s", r.Method, r.URL.Path, path)
+					_ = json.NewEncoder(rw).Encode(map[string]string{
+						"type":    "file",
+						"path":    parts[5],
+						"content": string(b),
+					})
+					return
+				}
+			}
+		}
+		log.Printf("github: %s %s: no fixture, returning 404", r.Method, r.URL.Path)
+		rw.WriteHeader(http.StatusNotFound)
+		_, _ = rw.Write([]byte(`{"message": "Not Found"}`))
+	})
+}
@@ -2,30 +2,244 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"flag"
-	"os"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github"
+	triggersclientset "github.com/tektoncd/triggers/pkg/client/clientset/versioned"
+	triggersv1alpha1 "github.com/tektoncd/triggers/pkg/client/clientset/versioned/typed/triggers/v1alpha1"
+	"github.com/tektoncd/triggers/pkg/interceptors/server"
+	"github.com/tektoncd/triggers/pkg/interceptors/server/tlsconfig"
+	"go.uber.org/zap"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	"knative.dev/pkg/injection"
+	"knative.dev/pkg/logging"
+	"knative.dev/pkg/signals"
+	"knative.dev/pkg/system"
+	certresources "knative.dev/pkg/webhook/certificates/resources"
+)
+
+const (
+	// interceptorName is the name of the ClusterInterceptor, and the path the
+	// interceptor is served at on the interceptor server.
+	interceptorName = "github-simple"
+	readTimeout     = 5 * time.Second
+	writeTimeout    = 20 * time.Second
+	idleTimeout     = 60 * time.Second
 )
 
 var (
-	webhookSecretPath = flag.String("webhook_secret_path", "", "path to file containing webhook secret to validate")
+	webhookSecretPath = flag.String("webhook_secret_path", "", "path to file containing webhook secrets to validate, one per line. The file is reloaded when it changes")
+	requireSignature  = flag.Bool("require_signature", false, "reject webhook payloads that are not signed if --webhook_secret_path is set")
+	deliveryCacheSize = flag.Int("delivery_cache_size", 10000, "number of webhook deliveries to remember to reject replays, 0 to disable")
+	githubAppID       = flag.Int64("github_app_id", 0, "ID of the GitHub App to authenticate as for events sent by its installations")
+	githubAppKeyPath  = flag.String("github_app_private_key_path", "", "path to file containing the private key of the GitHub App")
+	githubTokenPath   = flag.String("github_token_path", "", "path to file containing a personal access token used for events not sent by a GitHub App installation")
+	cacheSizeMB       = flag.Int64("cache_size_mb", 64, "size of the in-memory cache of GitHub API responses, 0 to disable")
+	rateLimitWait     = flag.Duration("rate_limit_wait", 10*time.Second, "how long GitHub API calls can wait for an exhausted rate limit to reset before failing")
+	githubURL         = flag.String("github_url", "", "base URL of the GitHub API, for GitHub Enterprise (e.g. https://github.example.com/api/v3/)")
+	scmDriver         = flag.String("scm_driver", "", "go-scm driver of another SCM to handle events of: gitea, gitlab, bitbucket or stash")
+	scmURL            = flag.String("scm_url", "", "base URL of the SCM set by --scm_driver (e.g. https://gitea.example.com)")
+	scmTokenPath      = flag.String("scm_token_path", "", "path to file containing the token used to call the API of the SCM set by --scm_driver")
+	metricsPort       = flag.Int("metrics_port", 9090, "port Prometheus metrics are served on, at /metrics")
+	interceptorPort   = flag.Int("interceptor_port", 8082, "port the ClusterInterceptor endpoint is served on, at /github-simple")
+	serveTLS          = flag.Bool("tls", false, "serve the ClusterInterceptor endpoint over HTTPS, with certificates kept in the $INTERCEPTOR_TLS_SECRET_NAME Secret and rotated before they expire")
 )
 
 func main() {
 	flag.Parse()
 
-	var webhookSecret []byte
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	opts := []github.Option{
+		github.WithLogger(logger.Sugar()),
+		github.WithDeliveryCache(*deliveryCacheSize),
+		github.WithCache(*cacheSizeMB << 20),
+		github.WithRateLimitWait(*rateLimitWait),
+	}
 	if *webhookSecretPath != "" {
-		var err error
-		webhookSecret, err = os.ReadFile(*webhookSecretPath)
+		secrets, err := github.NewSecretFile(*webhookSecretPath)
 		if err != nil {
 			log.Fatal(err)
 		}
+		opts = append(opts, github.WithWebhookSecrets(secrets))
+		if *requireSignature {
+			opts = append(opts, github.WithSignatureRequired())
+		} else {
+			logger.Warn("accepting unsigned webhook payloads even though --webhook_secret_path is set, set --require_signature to reject them")
+		}
+	}
+	if *githubURL != "" {
+		u, err := url.Parse(*githubURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		opts = append(opts, github.WithBaseURL(u))
+	}
+	if *githubAppID != 0 {
+		key, err := os.ReadFile(*githubAppKeyPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		app, err := github.NewAppTokenSource(http.DefaultClient, *githubAppID, key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, github.WithAppAuth(app))
+	}
+	if *githubTokenPath != "" {
+		token, err := os.ReadFile(*githubTokenPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, github.WithToken(string(bytes.TrimSpace(token))))
+	}
+	if *scmDriver != "" {
+		var token []byte
+		if *scmTokenPath != "" {
+			var err error
+			if token, err = os.ReadFile(*scmTokenPath); err != nil {
+				log.Fatal(err)
+			}
+		}
+		opts = append(opts, github.WithSCMServer(*scmDriver, *scmURL, string(bytes.TrimSpace(token))))
+	}
+	s := github.New(http.DefaultClient, nil, opts...)
+
+	// set up signals so we handle the first shutdown signal gracefully
+	ctx := logging.WithLogger(signals.NewContext(), logger.Sugar())
+
+	metrics := http.NewServeMux()
+	metrics.Handle("/metrics", promhttp.Handler())
+	go func() {
+		logger.Fatal("metrics server failed", zap.Error(http.ListenAndServe(fmt.Sprintf(":%d", *metricsPort), metrics)))
+	}()
+	// The legacy endpoint serves triggers that call the interceptor as a
+	// webhook.
+	go func() {
+		logger.Fatal("server failed", zap.Error(newServer(ctx, 8080, s).ListenAndServe()))
+	}()
+	logger.Fatal("interceptor server failed", zap.Error(serveInterceptor(ctx, s)))
+}
+
+// serveInterceptor serves s as a ClusterInterceptor on the Triggers
+// interceptor server.
+func serveInterceptor(ctx context.Context, s *github.Server) error {
+	logger := logging.FromContext(ctx)
+	is := &server.Server{
+		Logger: logger,
+	}
+	is.RegisterInterceptor(interceptorName, s)
+	mux := http.NewServeMux()
+	mux.Handle("/", is)
+	mux.HandleFunc("/ready", handler)
+	srv := newServer(ctx, *interceptorPort, mux)
+
+	if !*serveTLS {
+		logger.Infof("Listen and serve on port %d", *interceptorPort)
+		return srv.ListenAndServe()
+	}
+
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return fmt.Errorf("error reading cluster config: %w", err)
+	}
+	ctx, startInformers := injection.EnableInjectionOrDie(ctx, cfg)
+	startInformers()
+	tc, err := triggersclientset.NewForConfig(cfg)
+	if err != nil {
+		return fmt.Errorf("error creating triggers client: %w", err)
+	}
+	// Creates the certificates if needed, sets the CA bundle of the
+	// ClusterInterceptor, and recreates them when they expire.
+	server.CreateAndValidateCerts(ctx, kubeclient.Get(ctx).CoreV1(), logger, is, tc.TriggersV1alpha1())
+	go updateCABundle(ctx, tc.TriggersV1alpha1(), time.Minute)
+
+	srv.TLSConfig, err = tlsconfig.LoadFromEnv().ToTLSConfig()
+	if err != nil {
+		return fmt.Errorf("error reading TLS config: %w", err)
+	}
+	srv.TLSConfig.GetCertificate = func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+		return server.GetTLSData(ctx, logger)
+	}
+	logger.Infof("Listen and serve TLS on port %d", *interceptorPort)
+	return srv.ListenAndServeTLS("", "")
+}
+
+// updateCABundle keeps the CA bundle of the ClusterInterceptor up to date
+// with the certificates in the $INTERCEPTOR_TLS_SECRET_NAME Secret. Unlike
+// server.UpdateCACertToClusterInterceptorCRD, which stops at the first error
+// without reporting it, errors are logged and the update is retried.
+func updateCABundle(ctx context.Context, tc triggersv1alpha1.TriggersV1alpha1Interface, every time.Duration) {
+	logger := logging.FromContext(ctx)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if err := syncCABundle(ctx, tc); err != nil {
+			logger.Errorf("error updating the CA bundle of ClusterInterceptor %s: %v", interceptorName, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func syncCABundle(ctx context.Context, tc triggersv1alpha1.TriggersV1alpha1Interface) error {
+	name := os.Getenv("INTERCEPTOR_TLS_SECRET_NAME")
+	secret, err := kubeclient.Get(ctx).CoreV1().Secrets(system.Namespace()).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	ca, ok := secret.Data[certresources.CACert]
+	if !ok {
+		return fmt.Errorf("secret %s has no %s", name, certresources.CACert)
 	}
-	s := github.New(http.DefaultClient, bytes.TrimSpace(webhookSecret))
+	ci, err := tc.ClusterInterceptors().Get(ctx, interceptorName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(ci.Spec.ClientConfig.CaBundle, ca) {
+		return nil
+	}
+	ci.Spec.ClientConfig.CaBundle = ca
+	_, err = tc.ClusterInterceptors().Update(ctx, ci, metav1.UpdateOptions{})
+	return err
+}
+
+func newServer(ctx context.Context, port int, h http.Handler) *http.Server {
+	return &http.Server{
+		Addr: fmt.Sprintf(":%d", port),
+		BaseContext: func(listener net.Listener) context.Context {
+			return ctx
+		},
+		ReadHeaderTimeout: readTimeout,
+		ReadTimeout:       readTimeout,
+		WriteTimeout:      writeTimeout,
+		IdleTimeout:       idleTimeout,
+		Handler:           h,
+	}
+}
 
-	log.Fatal(http.ListenAndServe(":8080", s))
+func handler(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,213 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v34/github"
+)
+
+const (
+	// appJWTLifetime is how long the JWTs authenticating as the GitHub App
+	// are valid for. GitHub allows at most 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+	// tokenRefreshMargin is how long before they expire cached installation
+	// tokens are replaced, so that a token does not expire mid-request.
+	tokenRefreshMargin = 5 * time.Minute
+)
+
+// Option configures a Server.
+type Option func(*Server)
+
+// WithBaseURL points the GitHub clients at a different API endpoint, such
+// as a GitHub Enterprise instance. The url must have a trailing slash.
+func WithBaseURL(u *url.URL) Option {
+	return func(s *Server) {
+		s.baseURL = u
+	}
+}
+
+// WithAppAuth authenticates GitHub API calls for events sent by a GitHub
+// App installation with a token minted for that installation.
+func WithAppAuth(app *AppTokenSource) Option {
+	return func(s *Server) {
+		s.app = app
+	}
+}
+
+// WithToken authenticates GitHub API calls with a personal access token.
+// If App auth is also configured, the token is only used for events that
+// do not come from an installation.
+func WithToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// githubClient returns the client used to handle an event sent by the
+// given installation (0 if the event did not come from a GitHub App).
+func (s *Server) githubClient(ctx context.Context, installation int64) (*github.Client, error) {
+	token := s.token
+	if s.app != nil && installation != 0 {
+		var err error
+		token, err = s.app.Token(ctx, installation)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hc := &http.Client{
+		Transport: s.transport(),
+		Timeout:   s.client.Timeout,
+	}
+	if token != "" {
+		hc.Transport = &tokenTransport{token: token, base: hc.Transport}
+	}
+	client := github.NewClient(hc)
+	if s.baseURL != nil {
+		client.BaseURL = s.baseURL
+	}
+	return client, nil
+}
+
+// tokenTransport adds a token to the Authorization header of requests.
+type tokenTransport struct {
+	token string
+	// scheme defaults to "token", the scheme GitHub uses for OAuth and
+	// installation tokens.
+	scheme string
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	scheme := t.scheme
+	if scheme == "" {
+		scheme = "token"
+	}
+	// RoundTrippers must not modify the original request.
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", scheme+" "+t.token)
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(r)
+}
+
+// AppTokenSource mints installation tokens for a GitHub App. Tokens are
+// cached per installation and refreshed shortly before they expire.
+type AppTokenSource struct {
+	appID   int64
+	key     *rsa.PrivateKey
+	client  *http.Client
+	baseURL *url.URL
+	now     func() time.Time
+
+	mu     sync.Mutex
+	tokens map[int64]*github.InstallationToken
+}
+
+// NewAppTokenSource returns an AppTokenSource for the App with the given
+// ID, authenticating with its PEM encoded private key.
+func NewAppTokenSource(c *http.Client, appID int64, privateKey []byte) (*AppTokenSource, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	return &AppTokenSource{
+		appID:  appID,
+		key:    key,
+		client: c,
+		now:    time.Now,
+		tokens: make(map[int64]*github.InstallationToken),
+	}, nil
+}
+
+// parsePrivateKey reads a PKCS1 or PKCS8 encoded RSA private key. GitHub
+// issues App keys in PKCS1.
+func parsePrivateKey(b []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing private key: %w", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// Token returns a valid token for the installation.
+func (s *AppTokenSource) Token(ctx context.Context, installation int64) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if t, ok := s.tokens[installation]; ok && s.now().Add(tokenRefreshMargin).Before(t.GetExpiresAt()) {
+		return t.GetToken(), nil
+	}
+
+	jwt, err := s.jwt()
+	if err != nil {
+		return "", err
+	}
+	client := github.NewClient(&http.Client{
+		Transport: &tokenTransport{token: jwt, scheme: "Bearer", base: s.client.Transport},
+		Timeout:   s.client.Timeout,
+	})
+	if s.baseURL != nil {
+		client.BaseURL = s.baseURL
+	}
+	t, _, err := client.Apps.CreateInstallationToken(ctx, installation, nil)
+	if err != nil {
+		return "", fmt.Errorf("error creating token for installation %d: %w", installation, err)
+	}
+	s.tokens[installation] = t
+	return t.GetToken(), nil
+}
+
+// jwt returns a JWT authenticating as the App, signed with its private
+// key.
+func (s *AppTokenSource) jwt() (string, error) {
+	now := s.now()
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		// Backdate the token to allow for clock drift.
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing JWT: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
@@ -0,0 +1,198 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v34/github"
+)
+
+// fakeApp is a fake GitHub API minting installation tokens for an App.
+type fakeApp struct {
+	key     *rsa.PrivateKey
+	expires time.Time
+	minted  int
+	// auth records the Authorization header of requests to /user.
+	auth string
+}
+
+func newFakeApp(t *testing.T) (*fakeApp, *httptest.Server) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &fakeApp{key: key, expires: time.Now().Add(time.Hour)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/42/access_tokens", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if err := app.verifyJWT(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")); err != nil {
+			t.Error(err)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		app.minted++
+		rw.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(rw).Encode(&github.InstallationToken{
+			Token:     github.String(fmt.Sprintf("installation-token-%d", app.minted)),
+			ExpiresAt: &app.expires,
+		})
+	})
+	mux.HandleFunc("/user", func(rw http.ResponseWriter, r *http.Request) {
+		app.auth = r.Header.Get("Authorization")
+		_ = json.NewEncoder(rw).Encode(&github.User{Login: github.String("tekton-robot")})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return app, srv
+}
+
+func (a *fakeApp) privateKey() []byte {
+	return pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(a.key),
+	})
+}
+
+func (a *fakeApp) verifyJWT(jwt string) error {
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("malformed JWT %q", jwt)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&a.key.PublicKey, crypto.SHA256, digest[:], sig); err != nil {
+		return fmt.Errorf("invalid JWT signature: %v", err)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return err
+	}
+	var claims struct {
+		Iss string `json:"iss"`
+		Iat int64  `json:"iat"`
+		Exp int64  `json:"exp"`
+	}
+	if err := json.Unmarshal(b, &claims); err != nil {
+		return err
+	}
+	if claims.Iss != "1234" {
+		return fmt.Errorf("expected JWT issued by App 1234, got %q", claims.Iss)
+	}
+	if lifetime := time.Duration(claims.Exp-claims.Iat) * time.Second; lifetime > 10*time.Minute {
+		return fmt.Errorf("JWT is valid for longer than 10 minutes: %v", lifetime)
+	}
+	return nil
+}
+
+func TestAppTokenSource(t *testing.T) {
+	ctx := context.Background()
+	app, srv := newFakeApp(t)
+	ts, err := NewAppTokenSource(srv.Client(), 1234, app.privateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.baseURL = mustParseURL(srv.URL + "/")
+
+	for _, want := range []string{"installation-token-1", "installation-token-1"} {
+		got, err := ts.Token(ctx, 42)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("want token %s, got %s", want, got)
+		}
+	}
+	if app.minted != 1 {
+		t.Errorf("expected cached token to be reused, minted %d tokens", app.minted)
+	}
+
+	// Tokens about to expire are refreshed.
+	ts.now = func() time.Time { return app.expires.Add(-time.Minute) }
+	got, err := ts.Token(ctx, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "installation-token-2" {
+		t.Errorf("expected token to be refreshed, got %s", got)
+	}
+
+	if _, err := ts.Token(ctx, 7); err == nil {
+		t.Error("expected error for unknown installation")
+	}
+}
+
+func TestNewAppTokenSource_InvalidKey(t *testing.T) {
+	if _, err := NewAppTokenSource(http.DefaultClient, 1234, []byte("not a key")); err == nil {
+		t.Error("expected error for invalid private key")
+	}
+}
+
+func TestGitHubClient(t *testing.T) {
+	ctx := context.Background()
+	app, srv := newFakeApp(t)
+	ts, err := NewAppTokenSource(srv.Client(), 1234, app.privateKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name         string
+		opts         []Option
+		installation int64
+		want         string
+	}{
+		{
+			name: "anonymous",
+		},
+		{
+			name: "personal access token",
+			opts: []Option{WithToken("pat")},
+			want: "token pat",
+		},
+		{
+			name:         "app installation",
+			opts:         []Option{WithAppAuth(ts), WithToken("pat")},
+			installation: 42,
+			want:         "token installation-token-1",
+		},
+		{
+			name: "personal access token without installation",
+			opts: []Option{WithAppAuth(ts), WithToken("pat")},
+			want: "token pat",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(srv.Client(), nil, append(tc.opts, WithBaseURL(mustParseURL(srv.URL+"/")))...)
+			client, err := s.githubClient(ctx, tc.installation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, _, err := client.Users.Get(ctx, ""); err != nil {
+				t.Fatal(err)
+			}
+			if app.auth != tc.want {
+				t.Errorf("want Authorization %q, got %q", tc.want, app.auth)
+			}
+		})
+	}
+}
@@ -1,12 +1,62 @@
+// Package bindings defines the extensions the interceptor adds for use in
+// TriggerBindings.
 package bindings
 
+// Version is the version of the schema of the git and github extensions.
+// Fields may be added within a version, but are never removed or changed.
+const Version = "v1"
+
 type Git struct {
 	URL      string `json:"url,omitempty"`
 	Revision string `json:"revision,omitempty"`
+	// Ref is the ref to fetch Revision from, e.g. "refs/heads/main" or
+	// "refs/pull/1/head".
+	Ref string `json:"ref,omitempty"`
+	// Depth is the clone depth needed to fetch the changes being built
+	// along with their base, or 0 if unknown.
+	Depth int `json:"depth"`
 }
 
 type GitHub struct {
 	Owner        string `json:"owner,omitempty"`
 	Repo         string `json:"repo,omitempty"`
 	Installation int64  `json:"installation,omitempty"`
+	// PullRequest is set for pull request related events.
+	PullRequest *PullRequest `json:"pull_request,omitempty"`
+}
+
+// PullRequest summarizes a pull request.
+type PullRequest struct {
+	Number int    `json:"number"`
+	Author string `json:"author"`
+	// BaseURL is the clone URL of the base repository, which has the pull
+	// request refs, including for pull requests from forks.
+	BaseURL string `json:"base_url"`
+	// BaseRef is the branch the pull request merges into, without the
+	// "refs/heads/" prefix, and BaseSHA its commit.
+	BaseRef string `json:"base_ref"`
+	BaseSHA string `json:"base_sha"`
+	// HeadRef is the branch the pull request merges from, in the head
+	// repository, and HeadSHA its commit.
+	HeadRef string   `json:"head_ref"`
+	HeadSHA string   `json:"head_sha"`
+	Labels  []string `json:"labels"`
+	Draft   bool     `json:"draft"`
+	// Commits is the number of commits of the pull request, or 0 if unknown.
+	Commits int `json:"commits"`
+	// PullRef is the ref of the pull request head in the base repository,
+	// e.g. "refs/pull/1/head".
+	PullRef string `json:"pull_ref"`
+	// MergeRef is the ref of the test merge commit GitHub creates, e.g.
+	// "refs/pull/1/merge". It may not exist yet, or at all if the pull
+	// request has conflicts.
+	MergeRef string `json:"merge_ref"`
+}
+
+// Command is a slash command from a comment, e.g. "/test e2e --go=1.22".
+// Args and Flags are always set so that bindings can reference them.
+type Command struct {
+	Name  string            `json:"name"`
+	Args  []string          `json:"args"`
+	Flags map[string]string `json:"flags"`
 }
@@ -0,0 +1,170 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"github.com/google/go-github/v34/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+// zeroSHA is the commit GitHub reports as the before or after state of refs
+// that were created or deleted.
+const zeroSHA = "0000000000000000000000000000000000000000"
+
+const (
+	// maxPullRequestFiles is the most files GitHub lists for a pull request.
+	maxPullRequestFiles = 3000
+	// maxCompareFiles is the most files GitHub lists for a comparison.
+	maxCompareFiles = 300
+)
+
+// errTruncated is returned if not all changed files could be listed.
+var errTruncated = errors.New("too many changed files to list all of them")
+
+// pathMatcher reports whether a file is matched by a Paths config.
+type pathMatcher struct {
+	include []glob.Glob
+	exclude []glob.Glob
+}
+
+func newPathMatcher(cfg *pb.Paths) (*pathMatcher, error) {
+	include := cfg.GetInclude()
+	if len(include) == 0 {
+		include = []string{"**"}
+	}
+	m := new(pathMatcher)
+	var err error
+	if m.include, err = compilePaths(include); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = compilePaths(cfg.GetExclude()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// compilePaths compiles path globs. Patterns starting with "**/" also match
+// files in the repo root.
+func compilePaths(patterns []string) ([]glob.Glob, error) {
+	var out []glob.Glob
+	for _, p := range patterns {
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			return nil, Errorf(codes.InvalidArgument, "invalid path pattern %q: %v", p, err)
+		}
+		out = append(out, g)
+		if rest := strings.TrimPrefix(p, "**/"); rest != p {
+			g, err := glob.Compile(rest, '/')
+			if err != nil {
+				return nil, Errorf(codes.InvalidArgument, "invalid path pattern %q: %v", p, err)
+			}
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func matchAny(globs []glob.Glob, file string) bool {
+	for _, g := range globs {
+		if g.Match(file) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match reports whether any of the files is included and not excluded.
+func (m *pathMatcher) Match(files []string) bool {
+	for _, f := range files {
+		if matchAny(m.include, f) && !matchAny(m.exclude, f) {
+			return true
+		}
+	}
+	return false
+}
+
+// commitFileNames returns the names of changed files. Renamed files are
+// listed under both their old and new names.
+func commitFileNames(files []*github.CommitFile) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, f.GetFilename())
+		if prev := f.GetPreviousFilename(); prev != "" {
+			names = append(names, prev)
+		}
+	}
+	return names
+}
+
+// pullRequestFiles lists the files changed by a pull request. GitHub lists
+// at most 3000 files, errTruncated is returned if there may be more.
+func pullRequestFiles(ctx context.Context, client *github.Client, owner, repo string, number int) ([]string, error) {
+	var files []*github.CommitFile
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		page, resp, err := client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, page...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	if len(files) >= maxPullRequestFiles {
+		return nil, errTruncated
+	}
+	return commitFileNames(files), nil
+}
+
+// pushFiles lists the files changed between the before and after commits
+// of a push. GitHub lists at most 300 files, errTruncated is returned if
+// there may be more.
+func pushFiles(ctx context.Context, client *github.Client, owner, repo, before, after string) ([]string, error) {
+	cmp, _, err := client.Repositories.CompareCommits(ctx, owner, repo, before, after)
+	if err != nil {
+		return nil, err
+	}
+	if len(cmp.Files) >= maxCompareFiles {
+		return nil, errTruncated
+	}
+	return commitFileNames(cmp.Files), nil
+}
+
+// filterPaths checks whether the changed files of an event match the paths
+// config. listFiles is only called if paths are configured, in which case
+// the changed files are returned. A response is returned if the event
+// should not run. Events changing too many files to list always run, without
+// changed files.
+func filterPaths(cfg *pb.Paths, listFiles func() ([]string, error)) ([]string, *v1alpha1.InterceptorResponse, error) {
+	if cfg == nil {
+		return nil, nil, nil
+	}
+	m, err := newPathMatcher(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := listFiles()
+	if errors.Is(err, errTruncated) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, Errorf(codes.Unavailable, "error listing changed files: %v", err)
+	}
+	if !m.Match(files) {
+		return files, &v1alpha1.InterceptorResponse{
+			Continue: false,
+			Status: v1alpha1.Status{
+				Code:    codes.FailedPrecondition,
+				Message: "no changed files match the configured paths",
+			},
+		}, nil
+	}
+	return files, nil, nil
+}
@@ -0,0 +1,263 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v34/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestPathMatcher(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		paths *pb.Paths
+		files []string
+		want  bool
+	}{
+		{
+			name:  "default includes everything",
+			paths: &pb.Paths{},
+			files: []string{"README.md"},
+			want:  true,
+		},
+		{
+			name:  "no files",
+			paths: &pb.Paths{},
+			want:  false,
+		},
+		{
+			name:  "directory",
+			paths: &pb.Paths{Include: []string{"docs/**"}},
+			files: []string{"cmd/main.go", "docs/a/b.md"},
+			want:  true,
+		},
+		{
+			name:  "single star does not cross directories",
+			paths: &pb.Paths{Include: []string{"docs/*"}},
+			files: []string{"docs/a/b.md"},
+			want:  false,
+		},
+		{
+			name:  "double star prefix matches root",
+			paths: &pb.Paths{Include: []string{"**/*.go"}},
+			files: []string{"main.go"},
+			want:  true,
+		},
+		{
+			name:  "double star prefix matches subdirectories",
+			paths: &pb.Paths{Include: []string{"**/*.go"}},
+			files: []string{"pkg/github/server.go"},
+			want:  true,
+		},
+		{
+			name:  "all files excluded",
+			paths: &pb.Paths{Exclude: []string{"**/*.md", "docs/**"}},
+			files: []string{"README.md", "docs/install.md", "docs/img.png"},
+			want:  false,
+		},
+		{
+			name:  "some files not excluded",
+			paths: &pb.Paths{Exclude: []string{"**/*.md"}},
+			files: []string{"README.md", "main.go"},
+			want:  true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := newPathMatcher(tc.paths)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.Match(tc.files); got != tc.want {
+				t.Errorf("Match(%v) = %t, want %t", tc.files, got, tc.want)
+			}
+		})
+	}
+
+	if _, err := newPathMatcher(&pb.Paths{Include: []string{"[a-"}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestExecute_PullRequestPaths(t *testing.T) {
+	ctx := context.Background()
+	h := &PullRequest{}
+
+	// Serve 150 files over two pages.
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := github.NewClient(srv.Client())
+	client.BaseURL = mustParseURL(srv.URL + "/")
+	mux.HandleFunc("/repos/Codertocat/Hello-World/pulls/2/files", func(rw http.ResponseWriter, r *http.Request) {
+		var files []*github.CommitFile
+		if r.URL.Query().Get("page") == "2" {
+			files = []*github.CommitFile{
+				{Filename: github.String("pkg/new.go"), PreviousFilename: github.String("pkg/old.go")},
+			}
+		} else {
+			rw.Header().Set("Link", `<`+srv.URL+`/repos/Codertocat/Hello-World/pulls/2/files?page=2>; rel="next"`)
+			for i := 0; i < 100; i++ {
+				files = append(files, &github.CommitFile{Filename: github.String("docs/" + strconv.Itoa(i) + ".md")})
+			}
+		}
+		_ = json.NewEncoder(rw).Encode(files)
+	})
+
+	f, err := os.ReadFile("testdata/pull_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &v1alpha1.InterceptorRequest{
+		Body: string(f),
+		Header: map[string][]string{
+			"X-Github-Event": {"pull_request"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name  string
+		paths *pb.Paths
+		ok    bool
+	}{
+		{
+			name:  "renamed file matches old name",
+			paths: &pb.Paths{Include: []string{"pkg/old.go"}},
+			ok:    true,
+		},
+		{
+			name:  "file on second page",
+			paths: &pb.Paths{Include: []string{"**/*.go"}},
+			ok:    true,
+		},
+		{
+			name:  "nothing relevant changed",
+			paths: &pb.Paths{Include: []string{"test/**"}},
+			ok:    false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := h.Execute(ctx, client, &pb.Config{PullRequest: &pb.PullRequestConfig{Paths: tc.paths}}, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Continue != tc.ok {
+				t.Fatalf("want Continue %t, got %+v", tc.ok, resp)
+			}
+			if !tc.ok {
+				return
+			}
+			files, ok := resp.Extensions["changed_files"].([]string)
+			if !ok || len(files) != 102 {
+				t.Errorf("expected 102 changed files in extensions, got %v", resp.Extensions["changed_files"])
+			}
+		})
+	}
+}
+
+func TestExecute_PushPaths(t *testing.T) {
+	ctx := context.Background()
+	h := &Push{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := github.NewClient(srv.Client())
+	client.BaseURL = mustParseURL(srv.URL + "/")
+	mux.HandleFunc("/repos/Codertocat/Hello-World/compare/", func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/Codertocat/Hello-World/compare/abc123...6113728f27ae82c7b1a177c8d03f9e96e0adf246":
+			_ = json.NewEncoder(rw).Encode(&github.CommitsComparison{
+				Files: []*github.CommitFile{
+					{Filename: github.String("README.md")},
+					{Filename: github.String("cmd/main.go")},
+				},
+			})
+		case "/repos/Codertocat/Hello-World/compare/def456...6113728f27ae82c7b1a177c8d03f9e96e0adf246":
+			// GitHub lists at most 300 files.
+			var files []*github.CommitFile
+			for i := 0; i < 300; i++ {
+				files = append(files, &github.CommitFile{Filename: github.String("docs/" + strconv.Itoa(i) + ".md")})
+			}
+			_ = json.NewEncoder(rw).Encode(&github.CommitsComparison{Files: files})
+		default:
+			rw.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	f, err := os.ReadFile("testdata/push.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := new(github.PushEvent)
+	if err := json.Unmarshal(f, event); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		before string
+		paths  *pb.Paths
+		ok     bool
+		files  []string
+	}{
+		{
+			name:   "matching files",
+			before: "abc123",
+			paths:  &pb.Paths{Include: []string{"cmd/**"}},
+			ok:     true,
+			files:  []string{"README.md", "cmd/main.go"},
+		},
+		{
+			name:   "only excluded files",
+			before: "abc123",
+			paths:  &pb.Paths{Exclude: []string{"**/*.md", "cmd/**"}},
+			ok:     false,
+		},
+		{
+			name:   "too many files to list",
+			before: "def456",
+			paths:  &pb.Paths{Include: []string{"test/**"}},
+			ok:     true,
+		},
+		{
+			name:   "new refs always run",
+			before: zeroSHA,
+			paths:  &pb.Paths{Include: []string{"test/**"}},
+			ok:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event.Before = github.String(tc.before)
+			body, err := json.Marshal(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &v1alpha1.InterceptorRequest{Body: string(body)}
+			resp, err := h.Execute(ctx, client, &pb.Config{Push: &pb.PushConfig{Paths: tc.paths}}, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Continue != tc.ok {
+				t.Fatalf("want Continue %t, got %+v", tc.ok, resp)
+			}
+			if !tc.ok {
+				return
+			}
+			var files []string
+			if v, ok := resp.Extensions["changed_files"]; ok {
+				files = v.([]string)
+			}
+			if diff := cmp.Diff(tc.files, files); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/go-github/v34/github"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	_ = Interceptor(&CheckRun{})
+	_ = Interceptor(&CheckSuite{})
+)
+
+// CheckRun re-runs a check run when it is re-requested from the GitHub UI.
+type CheckRun struct{}
+
+func (c *CheckRun) Execute(ctx context.Context, client *github.Client, cfg *pb.Config, req *v1alpha1.InterceptorRequest) (*v1alpha1.InterceptorResponse, error) {
+	event := new(github.CheckRunEvent)
+	if err := json.Unmarshal([]byte(req.Body), event); err != nil {
+		return nil, Error(codes.InvalidArgument, err.Error())
+	}
+
+	if cfg.GetCheckRun() == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for check_run")
+	}
+
+	if event.GetAction() != "rerequested" {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", event.GetAction())
+	}
+
+	patterns := cfg.GetCheckRun().GetName()
+	if patterns == nil {
+		// Default to all check runs.
+		patterns = []string{"*"}
+	}
+	cr := event.GetCheckRun()
+	if !matchGlob(patterns, cr.GetName()) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching check run name")
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			"check_run": cr,
+			"git": bindings.Git{
+				URL:      event.GetRepo().GetCloneURL(),
+				Revision: cr.GetHeadSHA(),
+				Ref:      branchRef(cr.GetCheckSuite().GetHeadBranch()),
+			},
+			"github": bindings.GitHub{
+				Owner:        event.GetRepo().GetOwner().GetLogin(),
+				Repo:         event.GetRepo().GetName(),
+				Installation: event.GetInstallation().GetID(),
+			},
+		},
+	}, nil
+}
+
+// CheckSuite re-runs all checks of a suite when they are re-requested from
+// the GitHub UI.
+type CheckSuite struct{}
+
+func (c *CheckSuite) Execute(ctx context.Context, client *github.Client, cfg *pb.Config, req *v1alpha1.InterceptorRequest) (*v1alpha1.InterceptorResponse, error) {
+	event := new(github.CheckSuiteEvent)
+	if err := json.Unmarshal([]byte(req.Body), event); err != nil {
+		return nil, Error(codes.InvalidArgument, err.Error())
+	}
+
+	if cfg.GetCheckSuite() == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for check_suite")
+	}
+
+	if event.GetAction() != "rerequested" {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", event.GetAction())
+	}
+
+	patterns := cfg.GetCheckSuite().GetBranch()
+	if patterns == nil {
+		// Default to all branches.
+		patterns = []string{"**"}
+	}
+	cs := event.GetCheckSuite()
+	if !matchGlob(patterns, cs.GetHeadBranch()) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching branch pattern")
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			"check_suite": cs,
+			"git": bindings.Git{
+				URL:      event.GetRepo().GetCloneURL(),
+				Revision: cs.GetHeadSHA(),
+				Ref:      branchRef(cs.GetHeadBranch()),
+			},
+			"github": bindings.GitHub{
+				Owner:        event.GetRepo().GetOwner().GetLogin(),
+				Repo:         event.GetRepo().GetName(),
+				Installation: event.GetInstallation().GetID(),
+			},
+		},
+	}, nil
+}
+
+// branchRef returns the ref of a branch, or "" if the branch is unknown.
+func branchRef(branch string) string {
+	if branch == "" {
+		return ""
+	}
+	return "refs/heads/" + branch
+}
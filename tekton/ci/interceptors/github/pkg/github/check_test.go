@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestExecute_CheckRun(t *testing.T) {
+	ctx := context.Background()
+	h := &CheckRun{}
+
+	f, err := os.ReadFile("testdata/check_run.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &v1alpha1.InterceptorRequest{
+		Body: string(f),
+		Header: map[string][]string{
+			"X-Github-Event": {"check_run"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		cfg  *pb.Config
+		ok   bool
+	}{
+		{
+			name: "default",
+			cfg: &pb.Config{
+				CheckRun: &pb.CheckRunConfig{},
+			},
+			ok: true,
+		},
+		{
+			name: "matching name",
+			cfg: &pb.Config{
+				CheckRun: &pb.CheckRunConfig{
+					Name: []string{"pull-plumbing-*"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "non-matching name",
+			cfg: &pb.Config{
+				CheckRun: &pb.CheckRunConfig{
+					Name: []string{"pull-pipeline-*"},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "no config",
+			cfg:  &pb.Config{},
+			ok:   false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := h.Execute(ctx, nil, tc.cfg, req)
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected failure, got (%+v, %v)", resp, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success: %t, got (%+v, %v)", tc.ok, resp, err)
+			}
+
+			if diff := cmp.Diff(bindings.Git{
+				URL:      "https://github.com/Codertocat/Hello-World.git",
+				Revision: "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+				Ref:      "refs/heads/changes",
+			}, resp.Extensions["git"]); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(bindings.GitHub{
+				Owner:        "Codertocat",
+				Repo:         "Hello-World",
+				Installation: 2311213,
+			}, resp.Extensions["github"]); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestExecute_CheckSuite(t *testing.T) {
+	ctx := context.Background()
+	h := &CheckSuite{}
+
+	f, err := os.ReadFile("testdata/check_suite.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &v1alpha1.InterceptorRequest{
+		Body: string(f),
+		Header: map[string][]string{
+			"X-Github-Event": {"check_suite"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name string
+		cfg  *pb.Config
+		ok   bool
+	}{
+		{
+			name: "default",
+			cfg: &pb.Config{
+				CheckSuite: &pb.CheckSuiteConfig{},
+			},
+			ok: true,
+		},
+		{
+			name: "matching branch",
+			cfg: &pb.Config{
+				CheckSuite: &pb.CheckSuiteConfig{
+					Branch: []string{"changes"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "non-matching branch",
+			cfg: &pb.Config{
+				CheckSuite: &pb.CheckSuiteConfig{
+					Branch: []string{"main"},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "no config",
+			cfg:  &pb.Config{},
+			ok:   false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := h.Execute(ctx, nil, tc.cfg, req)
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected failure, got (%+v, %v)", resp, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success: %t, got (%+v, %v)", tc.ok, resp, err)
+			}
+
+			if diff := cmp.Diff(bindings.Git{
+				URL:      "https://github.com/Codertocat/Hello-World.git",
+				Revision: "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+				Ref:      "refs/heads/changes",
+			}, resp.Extensions["git"]); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
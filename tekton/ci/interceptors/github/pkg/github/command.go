@@ -0,0 +1,126 @@
+package github
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+)
+
+// commandRE matches a slash command at the start of a line, e.g.
+// "/ok-to-test".
+var commandRE = regexp.MustCompile(`^/([A-Za-z0-9][A-Za-z0-9_-]*)(?:\s+(.*))?$`)
+
+// command is a parsed slash command, with the line it was parsed from.
+type command struct {
+	bindings.Command
+	line string
+}
+
+// parseCommands returns the slash commands in a comment, one per line.
+// Quoted lines ("> /test") and fenced code blocks are ignored, so that
+// quoting a command in a reply does not run it again.
+//
+// Arguments are split on whitespace, and can be quoted with ' or ". Arguments
+// of the form "--key=value", "-key=value" or "key=value" are flags; "--key"
+// alone sets the flag to "true". Other arguments are positional.
+func parseCommands(body string) []command {
+	var cmds []command
+	inCode := false
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		m := commandRE.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		c := command{
+			Command: bindings.Command{
+				Name:  m[1],
+				Args:  []string{},
+				Flags: map[string]string{},
+			},
+			line: line,
+		}
+		for _, arg := range splitArgs(m[2]) {
+			key, value, isFlag := parseFlag(arg)
+			if isFlag {
+				c.Flags[key] = value
+			} else {
+				c.Args = append(c.Args, arg)
+			}
+		}
+		cmds = append(cmds, c)
+	}
+	return cmds
+}
+
+// splitArgs splits s on whitespace, keeping quoted strings together.
+// Unterminated quotes extend to the end of s.
+func splitArgs(s string) []string {
+	var (
+		args  []string
+		arg   strings.Builder
+		quote rune
+		inArg bool
+	)
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				arg.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+	return args
+}
+
+// parseFlag returns the key and value of a flag argument.
+func parseFlag(arg string) (string, string, bool) {
+	key, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	switch {
+	case key == "":
+		return "", "", false
+	case hasValue:
+		return key, value, true
+	case strings.HasPrefix(arg, "--"):
+		// Single dash arguments, such as "-1", are positional.
+		return key, "true", true
+	}
+	return "", "", false
+}
+
+// findCommand returns the first command with one of the given names.
+func findCommand(cmds []command, names []string) *command {
+	for i, c := range cmds {
+		for _, n := range names {
+			if strings.EqualFold(c.Name, strings.TrimPrefix(n, "/")) {
+				return &cmds[i]
+			}
+		}
+	}
+	return nil
+}
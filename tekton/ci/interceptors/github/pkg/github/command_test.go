@@ -0,0 +1,117 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+)
+
+func TestParseCommands(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		body string
+		want []bindings.Command
+	}{
+		{
+			name: "no commands",
+			body: "LGTM, thanks!",
+			want: []bindings.Command{},
+		},
+		{
+			name: "command without arguments",
+			body: "/ok-to-test",
+			want: []bindings.Command{
+				{Name: "ok-to-test", Args: []string{}, Flags: map[string]string{}},
+			},
+		},
+		{
+			name: "arguments and flags",
+			body: "/test e2e-kind --go=1.22 -race arch=arm64 --verbose",
+			want: []bindings.Command{
+				{
+					Name:  "test",
+					Args:  []string{"e2e-kind", "-race"},
+					Flags: map[string]string{"go": "1.22", "arch": "arm64", "verbose": "true"},
+				},
+			},
+		},
+		{
+			name: "quoted arguments",
+			body: `/test 'unit tests' --run="TestA TestB" "unterminated arg`,
+			want: []bindings.Command{
+				{
+					Name:  "test",
+					Args:  []string{"unit tests", "unterminated arg"},
+					Flags: map[string]string{"run": "TestA TestB"},
+				},
+			},
+		},
+		{
+			name: "multiple commands",
+			body: "Looks good.\r\n  /lgtm\n\n/test unit\t\tlint\n/ not a command",
+			want: []bindings.Command{
+				{Name: "lgtm", Args: []string{}, Flags: map[string]string{}},
+				{Name: "test", Args: []string{"unit", "lint"}, Flags: map[string]string{}},
+			},
+		},
+		{
+			name: "quotes and code blocks are ignored",
+			body: "> /test all\n```\n/test all\n```\n/retest",
+			want: []bindings.Command{
+				{Name: "retest", Args: []string{}, Flags: map[string]string{}},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []bindings.Command{}
+			for _, c := range parseCommands(tc.body) {
+				got = append(got, c.Command)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+func TestFindCommand(t *testing.T) {
+	cmds := parseCommands("/lgtm\n/Test unit\n/test e2e")
+	if c := findCommand(cmds, []string{"retest", "/test"}); c == nil || c.Args[0] != "unit" {
+		t.Errorf("expected first test command, got %+v", c)
+	}
+	if c := findCommand(cmds, []string{"retest"}); c != nil {
+		t.Errorf("expected no command, got %+v", c)
+	}
+}
+
+func TestMatchComment(t *testing.T) {
+	cfg := &pb.PullRequestConfig_CommentConfig{Match: "/ok-to-test"}
+	for _, tc := range []struct {
+		name string
+		body string
+		want bindings.Command
+	}{
+		{
+			name: "command",
+			body: "/ok-to-test --go=1.22",
+			want: bindings.Command{Name: "ok-to-test", Args: []string{}, Flags: map[string]string{"go": "1.22"}},
+		},
+		{
+			name: "quoted command",
+			body: "> /ok-to-test\nthanks",
+			want: bindings.Command{Args: []string{}, Flags: map[string]string{}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			cmds, cmd, err := matchComment(cfg, tc.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, commandExtensions(cmds, cmd)["command"]); diff != "" {
+				t.Errorf("-want +got: %s", diff)
+			}
+		})
+	}
+}
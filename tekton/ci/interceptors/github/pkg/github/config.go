@@ -2,8 +2,14 @@ package github
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
+	"github.com/gobwas/glob"
 	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
@@ -24,3 +30,70 @@ func Unmarshal(in map[string]interface{}) (*pb.Config, error) {
 	}
 	return cfg, nil
 }
+
+// reviewStates are the states of pull request reviews, as reported by the
+// webhook.
+var reviewStates = []string{"approved", "changes_requested", "commented"}
+
+// authorAssociations are the associations of pull request authors with a
+// repository, as reported by the webhook.
+var authorAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR", "CONTRIBUTOR", "FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "MANNEQUIN", "NONE"}
+
+// Validate checks that the patterns and values in a config are valid.
+// Invalid globs would otherwise never match.
+func Validate(cfg *pb.Config) error {
+	var errs []error
+	checkGlobs := func(field string, patterns []string) {
+		for _, p := range patterns {
+			if _, err := glob.Compile(p); err != nil {
+				errs = append(errs, fmt.Errorf("%s: invalid pattern %q: %v", field, p, err))
+			}
+		}
+	}
+	checkPaths := func(field string, paths *pb.Paths) {
+		if _, err := newPathMatcher(paths); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", field, err))
+		}
+	}
+
+	checkGlobs("push.ref", cfg.GetPush().GetRef())
+	checkPaths("push.paths", cfg.GetPush().GetPaths())
+
+	pr := cfg.GetPullRequest()
+	checkGlobs("pull_request.branch", pr.GetBranch())
+	checkPaths("pull_request.paths", pr.GetPaths())
+	if match := pr.GetComment().GetMatch(); match != "" {
+		if _, err := regexp.Compile(match); err != nil {
+			errs = append(errs, fmt.Errorf("pull_request.comment.match: invalid regex %q: %v", match, err))
+		}
+	}
+	for _, team := range pr.GetComment().GetTeams() {
+		if org, slug, ok := strings.Cut(team, "/"); !ok || org == "" || slug == "" {
+			errs = append(errs, fmt.Errorf("pull_request.comment.teams: %q is not of the form org/team-slug", team))
+		}
+	}
+	for _, a := range pr.GetComment().GetTrustedAssociations() {
+		if !containsString(authorAssociations, a) {
+			errs = append(errs, fmt.Errorf("pull_request.comment.trusted_associations: unknown association %q, must be one of %v", a, authorAssociations))
+		}
+	}
+	if len(pr.GetComment().GetRemoveLabelCommands()) > 0 && pr.GetComment().GetLabel() == "" {
+		errs = append(errs, fmt.Errorf("pull_request.comment.remove_label_commands: requires pull_request.comment.label"))
+	}
+
+	checkGlobs("check_run.name", cfg.GetCheckRun().GetName())
+	checkGlobs("check_suite.branch", cfg.GetCheckSuite().GetBranch())
+	checkGlobs("release.tag", cfg.GetRelease().GetTag())
+
+	checkGlobs("pull_request_review.branch", cfg.GetPullRequestReview().GetBranch())
+	for _, state := range cfg.GetPullRequestReview().GetState() {
+		if !containsString(reviewStates, state) {
+			errs = append(errs, fmt.Errorf("pull_request_review.state: unknown state %q, must be one of %v", state, reviewStates))
+		}
+	}
+
+	if len(errs) > 0 {
+		return Errorf(codes.InvalidArgument, "invalid config: %v", errors.Join(errs...))
+	}
+	return nil
+}
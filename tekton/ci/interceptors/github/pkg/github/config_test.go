@@ -0,0 +1,90 @@
+package github
+
+import (
+	"strings"
+	"testing"
+
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+)
+
+func TestValidate(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		cfg  *pb.Config
+		// errs are substrings of the expected error, none if empty.
+		errs []string
+	}{
+		{
+			name: "empty",
+			cfg:  &pb.Config{},
+		},
+		{
+			name: "valid",
+			cfg: &pb.Config{
+				Push: &pb.PushConfig{
+					Ref:   []string{"refs/heads/*", "refs/tags/v[0-9]*"},
+					Paths: &pb.Paths{Include: []string{"**/*.go"}},
+				},
+				PullRequest: &pb.PullRequestConfig{
+					Branch: []string{"release-*"},
+					Comment: &pb.PullRequestConfig_CommentConfig{
+						Match:               "^/ok-to-test",
+						Teams:               []string{"tektoncd/core"},
+						TrustAuthors:        true,
+						TrustedAssociations: []string{"MEMBER", "CONTRIBUTOR"},
+						Label:               "ok-to-test",
+						RemoveLabelCommands: []string{"hold"},
+					},
+				},
+				PullRequestReview: &pb.PullRequestReviewConfig{State: []string{"approved", "commented"}},
+			},
+		},
+		{
+			name: "invalid",
+			cfg: &pb.Config{
+				Push: &pb.PushConfig{
+					Ref:   []string{"refs/heads/[main"},
+					Paths: &pb.Paths{Exclude: []string{"docs/[a-"}},
+				},
+				PullRequest: &pb.PullRequestConfig{
+					Comment: &pb.PullRequestConfig_CommentConfig{
+						Match:               "(",
+						Teams:               []string{"core"},
+						TrustedAssociations: []string{"member"},
+						RemoveLabelCommands: []string{"hold"},
+					},
+				},
+				Release:           &pb.ReleaseConfig{Tag: []string{"v[1"}},
+				PullRequestReview: &pb.PullRequestReviewConfig{State: []string{"APPROVED"}},
+			},
+			errs: []string{
+				`push.ref: invalid pattern "refs/heads/[main"`,
+				`push.paths: invalid path pattern "docs/[a-"`,
+				`pull_request.comment.match: invalid regex "("`,
+				`pull_request.comment.teams: "core"`,
+				`pull_request.comment.trusted_associations: unknown association "member"`,
+				`pull_request.comment.remove_label_commands: requires pull_request.comment.label`,
+				`release.tag: invalid pattern "v[1"`,
+				`pull_request_review.state: unknown state "APPROVED"`,
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := Validate(tc.cfg)
+			if len(tc.errs) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			for _, e := range tc.errs {
+				if !strings.Contains(err.Error(), e) {
+					t.Errorf("expected error to contain %q, got %v", e, err)
+				}
+			}
+		})
+	}
+}
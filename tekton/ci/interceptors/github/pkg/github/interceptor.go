@@ -0,0 +1,50 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+)
+
+var _ v1beta1.InterceptorInterface = (*Server)(nil)
+
+// Process handles a request sent to a ClusterInterceptor, for registration
+// on the Triggers interceptor server. Requests are handled the same as the
+// ones sent to ServeHTTP.
+func (s *Server) Process(ctx context.Context, r *v1beta1.InterceptorRequest) *v1beta1.InterceptorResponse {
+	start := time.Now()
+	info := new(requestInfo)
+	resp := response(s.handle(ctx, fromV1beta1(r), info))
+	s.record(info, resp, time.Since(start))
+	return toV1beta1(resp)
+}
+
+func fromV1beta1(r *v1beta1.InterceptorRequest) *v1alpha1.InterceptorRequest {
+	in := &v1alpha1.InterceptorRequest{
+		Body:              r.Body,
+		Header:            r.Header,
+		Extensions:        r.Extensions,
+		InterceptorParams: r.InterceptorParams,
+	}
+	if r.Context != nil {
+		in.Context = &v1alpha1.TriggerContext{
+			EventURL:  r.Context.EventURL,
+			EventID:   r.Context.EventID,
+			TriggerID: r.Context.TriggerID,
+		}
+	}
+	return in
+}
+
+func toV1beta1(resp *v1alpha1.InterceptorResponse) *v1beta1.InterceptorResponse {
+	return &v1beta1.InterceptorResponse{
+		Extensions: resp.Extensions,
+		Continue:   resp.Continue,
+		Status: v1beta1.Status{
+			Code:    resp.Status.Code,
+			Message: resp.Status.Message,
+		},
+	}
+}
@@ -0,0 +1,111 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1beta1"
+	"github.com/tektoncd/triggers/pkg/interceptors/server"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+func TestServer_Process(t *testing.T) {
+	webhookSecret := []byte("hunter2")
+	body, err := os.ReadFile("testdata/push.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is := &server.Server{Logger: zap.NewNop().Sugar()}
+	is.RegisterInterceptor("github-simple", New(http.DefaultClient, webhookSecret))
+	srv := httptest.NewServer(is)
+	defer srv.Close()
+
+	for _, tc := range []struct {
+		name string
+		sig  string
+		cfg  *pb.Config
+		want *v1beta1.InterceptorResponse
+	}{
+		{
+			name: "continue",
+			sig:  signature(body, webhookSecret),
+			cfg:  &pb.Config{Push: &pb.PushConfig{}},
+			want: &v1beta1.InterceptorResponse{
+				Continue: true,
+				Extensions: map[string]interface{}{
+					"git": map[string]interface{}{
+						"url":      "https://github.com/Codertocat/Hello-World.git",
+						"revision": "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+						"ref":      "refs/tags/simple-tag",
+						"depth":    float64(0),
+					},
+					"github": map[string]interface{}{
+						"owner": "Codertocat",
+						"repo":  "Hello-World",
+					},
+				},
+			},
+		},
+		{
+			name: "deny",
+			sig:  signature(body, webhookSecret),
+			cfg:  &pb.Config{Push: &pb.PushConfig{Ref: []string{"refs/heads/release-*"}}},
+			want: &v1beta1.InterceptorResponse{
+				Status: v1beta1.Status{
+					Code:    codes.FailedPrecondition,
+					Message: "did not find matching ref pattern",
+				},
+			},
+		},
+		{
+			name: "bad signature",
+			sig:  signature(body, []byte("hunter3")),
+			cfg:  &pb.Config{Push: &pb.PushConfig{}},
+			want: &v1beta1.InterceptorResponse{
+				Status: v1beta1.Status{
+					Code:    codes.InvalidArgument,
+					Message: "unknown signature: payload signature check failed",
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := new(bytes.Buffer)
+			if err := json.NewEncoder(b).Encode(&v1beta1.InterceptorRequest{
+				Body: string(body),
+				Header: map[string][]string{
+					"X-Github-Event":      {"push"},
+					"X-Hub-Signature-256": {tc.sig},
+				},
+				InterceptorParams: map[string]interface{}{"config": tc.cfg},
+				Context:           &v1beta1.TriggerContext{EventID: tc.name},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := srv.Client().Post(srv.URL+"/github-simple", "application/json", b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("want status %d, got %d", http.StatusOK, resp.StatusCode)
+			}
+
+			got := new(v1beta1.InterceptorResponse)
+			if err := json.NewDecoder(resp.Body).Decode(got); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want +got: %s", diff)
+			}
+		})
+	}
+}
@@ -10,7 +10,6 @@ import (
 	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
 	"google.golang.org/grpc/codes"
-	"sigs.k8s.io/yaml"
 )
 
 var (
@@ -29,105 +28,99 @@ func (c *IssueComment) Execute(ctx context.Context, client *github.Client, cfg *
 		return nil, Errorf(codes.Unimplemented, "unsupported action")
 	}
 
+	// Issues and pull requests share comments, labels and numbers.
+	if !event.GetIssue().IsPullRequest() {
+		return nil, Error(codes.FailedPrecondition, "comment is not on a pull request")
+	}
+
 	commentCfg := cfg.GetPullRequest().GetComment()
 	if commentCfg == nil {
 		// No approver config - take no action (should be covered by PR handler)
 		return nil, Error(codes.FailedPrecondition, "comment config not enabled")
 	}
 
-	// Check if comment matches.
-	match := commentCfg.GetMatch()
-	if match == "" {
-		match = "/ok-to-test"
-	}
-	re, err := regexp.Compile(match)
-	if err != nil {
-		return nil, Error(codes.FailedPrecondition, "invalid match keyphrase")
-	}
-	if !re.MatchString(event.GetComment().GetBody()) {
-		return nil, Error(codes.FailedPrecondition, "comment does not match keyphrase")
-	}
-
-	// See if the comment came from an approved user. We do this after the
-	// comment match to save an API call if we can.
-	approverCfg := commentCfg.GetApprovers()
-	path := approverCfg.GetPath()
-	if path == "" {
-		path = "OWNERS"
-	}
 	org := event.GetRepo().GetOwner().GetLogin()
 	repo := event.GetRepo().GetName()
 	number := event.GetIssue().GetNumber()
+	body := event.GetComment().GetBody()
 	commentAuthor := event.GetComment().GetUser().GetLogin()
+	api := githubAPI{client}
 
-	fc, _, _, err := client.Repositories.GetContents(ctx, org, repo, path, &github.RepositoryContentGetOptions{Ref: approverCfg.GetRevision()})
-	if err != nil {
+	if err := checkRemoveLabel(ctx, api, commentCfg, org, repo, number, body, commentAuthor); err != nil {
 		return nil, err
 	}
-	owners, err := fc.GetContent()
+
+	// Check if comment matches.
+	cmds, cmd, err := matchComment(commentCfg, body)
 	if err != nil {
 		return nil, err
 	}
-	ok, err := containsOwner(owners, commentAuthor)
-	if err != nil {
-		return nil, Errorf(codes.InvalidArgument, "unable to read OWNERS file")
-	}
-	if !ok {
-		return nil, Errorf(codes.PermissionDenied, "user not allowed to approve trigger")
+
+	// See if the comment came from an approved user. We do this after the
+	// comment match to save an API call if we can.
+	if err := checkApprover(ctx, api, org, repo, commentApprovers(ctx, api, org, repo, number, commentCfg), commentAuthor); err != nil {
+		return nil, err
 	}
 
 	pr, _, err := client.PullRequests.Get(ctx, org, repo, number)
 	if err != nil {
 		return nil, err
 	}
+	addApprovalLabel(ctx, api, commentCfg, org, repo, number)
 
 	// Populate response w/ PR.
+	git, gh := pullRequestBindings(pr, event.GetInstallation().GetID())
+	ext := commandExtensions(cmds, cmd)
+	ext["pull_request"] = pr
+	ext["git"] = git
+	ext["github"] = gh
 	return &v1alpha1.InterceptorResponse{
-		Continue: true,
-		Extensions: map[string]interface{}{
-			"pull_request": pr,
-			"git": bindings.Git{
-				URL:      pr.GetHead().GetRepo().GetCloneURL(),
-				Revision: pr.GetHead().GetSHA(),
-			},
-			"github": bindings.GitHub{
-				Owner:        pr.GetBase().GetRepo().GetOwner().GetLogin(),
-				Repo:         pr.GetBase().GetRepo().GetName(),
-				Installation: event.GetInstallation().GetID(),
-			},
-		},
+		Continue:   true,
+		Extensions: ext,
 	}, nil
 }
 
-// config is a fork of the prow OWNERS config file.
-// See https://pkg.go.dev/k8s.io/test-infra/prow/repoowners#Config
-type config struct {
-	Approvers []string `json:"approvers,omitempty"`
-	Reviewers []string `json:"reviewers,omitempty"`
-}
-
-// loadSimpleConfig loads SimpleConfig from bytes `b`
-func loadConfig(b []byte) (config, error) {
-	simple := new(config)
-	err := yaml.Unmarshal(b, simple)
-	return *simple, err
-}
+// matchComment checks that a comment contains one of the configured
+// commands, or matches the configured keyphrase. It returns the commands of
+// the comment, and the one that matched. A comment matching the keyphrase
+// outside of a command, e.g. in a quote, matches an empty command.
+func matchComment(cfg *pb.PullRequestConfig_CommentConfig, body string) ([]command, *command, error) {
+	cmds := parseCommands(body)
+	if names := cfg.GetCommands(); len(names) > 0 {
+		cmd := findCommand(cmds, names)
+		if cmd == nil {
+			return nil, nil, Error(codes.FailedPrecondition, "comment does not contain a configured command")
+		}
+		return cmds, cmd, nil
+	}
 
-func containsOwner(content, owner string) (bool, error) {
-	cfg, err := loadConfig([]byte(content))
+	match := cfg.GetMatch()
+	if match == "" {
+		match = "/ok-to-test"
+	}
+	re, err := regexp.Compile(match)
 	if err != nil {
-		return false, err
+		return nil, nil, Error(codes.FailedPrecondition, "invalid match keyphrase")
 	}
-
-	for _, o := range cfg.Approvers {
-		if owner == o {
-			return true, nil
-		}
+	if !re.MatchString(body) {
+		return nil, nil, Error(codes.FailedPrecondition, "comment does not match keyphrase")
 	}
-	for _, o := range cfg.Reviewers {
-		if owner == o {
-			return true, nil
+	for i := range cmds {
+		if re.MatchString(cmds[i].line) {
+			return cmds, &cmds[i], nil
 		}
 	}
-	return false, nil
+	return cmds, &command{Command: bindings.Command{Args: []string{}, Flags: map[string]string{}}}, nil
+}
+
+// commandExtensions returns the "commands" and "command" extensions.
+func commandExtensions(cmds []command, cmd *command) map[string]interface{} {
+	commands := make([]bindings.Command, 0, len(cmds))
+	for _, c := range cmds {
+		commands = append(commands, c.Command)
+	}
+	return map[string]interface{}{
+		"commands": commands,
+		"command":  cmd.Command,
+	}
 }
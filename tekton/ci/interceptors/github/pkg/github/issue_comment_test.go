@@ -4,12 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path"
 	"testing"
 
 	_ "embed"
@@ -42,13 +44,20 @@ func TestExecute_IssueComment(t *testing.T) {
 		})
 	})
 	pr := &github.PullRequest{
+		Number:  github.Int(1),
+		User:    &github.User{Login: github.String("Codertocat")},
+		Labels:  []*github.Label{{Name: github.String("kind/bug")}},
+		Draft:   github.Bool(true),
+		Commits: github.Int(3),
 		Head: &github.PullRequestBranch{
+			Ref: github.String("fix"),
 			SHA: github.String("deadbeef"),
 			Repo: &github.Repository{
 				CloneURL: github.String("https://example.com/repo"),
 			},
 		},
 		Base: &github.PullRequestBranch{
+			Ref: github.String("main"),
 			SHA: github.String("deadbeef1"),
 			Repo: &github.Repository{
 				Owner: &github.User{
@@ -77,6 +86,8 @@ func TestExecute_IssueComment(t *testing.T) {
 		log.Fatal(err)
 	}
 
+	okToTest := bindings.Command{Name: "ok-to-test", Args: []string{}, Flags: map[string]string{}}
+
 	for _, tc := range []struct {
 		name string
 		cfg  *pb.Config
@@ -131,6 +142,28 @@ func TestExecute_IssueComment(t *testing.T) {
 			cfg:  &pb.Config{},
 			ok:   false,
 		},
+		{
+			name: "command",
+			cfg: &pb.Config{
+				PullRequest: &pb.PullRequestConfig{
+					Comment: &pb.PullRequestConfig_CommentConfig{
+						Commands: []string{"retest", "OK-to-test"},
+					},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "non-matching command",
+			cfg: &pb.Config{
+				PullRequest: &pb.PullRequestConfig{
+					Comment: &pb.PullRequestConfig_CommentConfig{
+						Commands: []string{"retest"},
+					},
+				},
+			},
+			ok: false,
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			resp, err := h.Execute(ctx, client, tc.cfg, req)
@@ -150,13 +183,30 @@ func TestExecute_IssueComment(t *testing.T) {
 			want := &v1alpha1.InterceptorResponse{
 				Continue: true,
 				Extensions: map[string]interface{}{
+					"command":  okToTest,
+					"commands": []bindings.Command{okToTest},
 					"git": bindings.Git{
 						URL:      "https://example.com/repo",
 						Revision: "deadbeef",
+						Ref:      "refs/pull/1/head",
+						Depth:    4,
 					},
 					"github": bindings.GitHub{
 						Owner: "Codertocat",
 						Repo:  "Hello-World",
+						PullRequest: &bindings.PullRequest{
+							Number:   1,
+							Author:   "Codertocat",
+							BaseRef:  "main",
+							BaseSHA:  "deadbeef1",
+							HeadRef:  "fix",
+							HeadSHA:  "deadbeef",
+							Labels:   []string{"kind/bug"},
+							Draft:    true,
+							Commits:  3,
+							PullRef:  "refs/pull/1/head",
+							MergeRef: "refs/pull/1/merge",
+						},
 					},
 					"pull_request": pr,
 				},
@@ -168,6 +218,178 @@ func TestExecute_IssueComment(t *testing.T) {
 	}
 }
 
+func TestExecute_IssueCommentLabel(t *testing.T) {
+	ctx := context.Background()
+	h := &IssueComment{}
+
+	var calls []string
+	var prErr bool
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := github.NewClient(srv.Client())
+	client.BaseURL = mustParseURL(srv.URL + "/")
+	mux.HandleFunc("/repos/tektoncd/results/contents/OWNERS", func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"type":    "file",
+			"content": ownersFile,
+		})
+	})
+	mux.HandleFunc("/repos/tektoncd/results/pulls/1", func(rw http.ResponseWriter, r *http.Request) {
+		if prErr {
+			rw.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(&github.PullRequest{Number: github.Int(1)})
+	})
+	mux.HandleFunc("/repos/tektoncd/results/issues/1/labels", func(rw http.ResponseWriter, r *http.Request) {
+		var labels []string
+		_ = json.NewDecoder(r.Body).Decode(&labels)
+		calls = append(calls, fmt.Sprintf("%s %v", r.Method, labels))
+		if len(labels) > 0 && labels[0] == "forbidden" {
+			rw.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode([]*github.Label{})
+	})
+	mux.HandleFunc("/repos/tektoncd/results/issues/1/labels/", func(rw http.ResponseWriter, r *http.Request) {
+		calls = append(calls, fmt.Sprintf("%s %s", r.Method, path.Base(r.URL.Path)))
+		rw.WriteHeader(http.StatusNotFound)
+	})
+
+	f, err := os.ReadFile("testdata/issue_comment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labelCfg := &pb.PullRequestConfig_CommentConfig{Label: "ok-to-test"}
+	for _, tc := range []struct {
+		name   string
+		cfg    *pb.PullRequestConfig_CommentConfig
+		body   string
+		author string
+		// issue comments on issues rather than pull requests.
+		issue bool
+		// prErr fails getting the pull request.
+		prErr bool
+		// message of the expected error, none if empty.
+		message string
+		calls   []string
+	}{
+		{
+			name:   "no label",
+			cfg:    &pb.PullRequestConfig_CommentConfig{},
+			body:   "/ok-to-test",
+			author: "Codercat",
+		},
+		{
+			name:   "approval adds label",
+			cfg:    labelCfg,
+			body:   "/ok-to-test",
+			author: "Codercat",
+			calls:  []string{"POST [ok-to-test]"},
+		},
+		{
+			name:   "label error does not block approval",
+			cfg:    &pb.PullRequestConfig_CommentConfig{Label: "forbidden"},
+			body:   "/ok-to-test",
+			author: "Codercat",
+			calls:  []string{"POST [forbidden]"},
+		},
+		{
+			name:    "comment on issue",
+			cfg:     labelCfg,
+			body:    "/ok-to-test",
+			author:  "Codercat",
+			issue:   true,
+			message: "comment is not on a pull request",
+		},
+		{
+			name:   "no label without pull request",
+			cfg:    labelCfg,
+			body:   "/ok-to-test",
+			author: "Codercat",
+			prErr:  true,
+		},
+		{
+			name:    "hold removes label",
+			cfg:     labelCfg,
+			body:    "/hold",
+			author:  "Codercat",
+			message: `removed label "ok-to-test"`,
+			calls:   []string{"DELETE ok-to-test"},
+		},
+		{
+			name:    "hold by non-approver",
+			cfg:     labelCfg,
+			body:    "/hold",
+			author:  "Outsider",
+			message: "user not allowed to approve trigger",
+		},
+		{
+			name:    "hold cancel",
+			cfg:     labelCfg,
+			body:    "/hold cancel",
+			author:  "Codercat",
+			message: "comment does not match keyphrase",
+		},
+		{
+			name: "configured command",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Label:               "ok-to-test",
+				RemoveLabelCommands: []string{"unapprove"},
+			},
+			body:    "/unapprove",
+			author:  "Codercat",
+			message: `removed label "ok-to-test"`,
+			calls:   []string{"DELETE ok-to-test"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = nil
+			prErr = tc.prErr
+			event := new(github.IssueCommentEvent)
+			if err := json.Unmarshal(f, event); err != nil {
+				t.Fatal(err)
+			}
+			event.Comment.Body = github.String(tc.body)
+			event.Comment.User.Login = github.String(tc.author)
+			if tc.issue {
+				event.Issue.PullRequestLinks = nil
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &v1alpha1.InterceptorRequest{
+				Body: string(body),
+				Header: map[string][]string{
+					"X-Github-Event": {"issue_comment"},
+				},
+			}
+
+			resp, err := h.Execute(ctx, client, &pb.Config{PullRequest: &pb.PullRequestConfig{Comment: tc.cfg}}, req)
+			if tc.prErr {
+				if err == nil {
+					t.Fatalf("expected error getting pull request, got %+v", resp)
+				}
+			} else if tc.message == "" {
+				if err != nil || !resp.Continue {
+					t.Fatalf("expected success, got (%+v, %v)", resp, err)
+				}
+			} else {
+				serr := new(StatusError)
+				if !errors.As(err, serr) || serr.Message != tc.message {
+					t.Fatalf("want error %q, got (%+v, %v)", tc.message, resp, err)
+				}
+			}
+			if diff := cmp.Diff(tc.calls, calls); diff != "" {
+				t.Errorf("label calls -want +got: %s", diff)
+			}
+		})
+	}
+}
+
 func mustParseURL(s string) *url.URL {
 	u, err := url.Parse(s)
 	if err != nil {
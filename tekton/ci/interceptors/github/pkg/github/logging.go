@@ -0,0 +1,86 @@
+package github
+
+import (
+	"context"
+	"time"
+
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+)
+
+// WithLogger logs the decision made for each request to logger. By default
+// nothing is logged.
+func WithLogger(logger *zap.SugaredLogger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+type loggerKey struct{}
+
+// withLogger returns a context carrying logger, for handlers to log what
+// does not change the decision made for a request.
+func withLogger(ctx context.Context, logger *zap.SugaredLogger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFrom returns the logger of ctx, or one logging nothing if it has
+// none.
+func loggerFrom(ctx context.Context) *zap.SugaredLogger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.SugaredLogger); ok {
+		return logger
+	}
+	return zap.NewNop().Sugar()
+}
+
+// requestInfo describes a request for logs and metrics. Fields are set as
+// the request is handled, and are empty if the request failed before they
+// were known.
+type requestInfo struct {
+	// delivery is the ID the SCM gave the delivery.
+	delivery string
+	// scm is the go-scm driver of the SCM that sent the event.
+	scm   string
+	event string
+	// repo is the full name of the repository, e.g. "tektoncd/plumbing".
+	repo string
+	// handler is the GitHub event type the request was handled as.
+	handler string
+}
+
+// record logs the decision made for a request, and updates the request
+// metrics.
+func (s *Server) record(info *requestInfo, resp *v1alpha1.InterceptorResponse, elapsed time.Duration) {
+	handler := info.handler
+	if handler == "" {
+		handler = "none"
+	}
+	if resp == nil {
+		resp = &v1alpha1.InterceptorResponse{}
+	}
+	decision := DecisionDeny
+	if resp.Continue {
+		decision = DecisionContinue
+	}
+	code := resp.Status.Code
+	requests.WithLabelValues(handler, decision, code.String()).Inc()
+	requestDuration.WithLabelValues(handler).Observe(elapsed.Seconds())
+
+	log := s.logger.Infow
+	switch code {
+	case codes.Internal, codes.Unavailable, codes.Unknown:
+		log = s.logger.Warnw
+	}
+	log("handled event",
+		"delivery", info.delivery,
+		"scm", info.scm,
+		"event", info.event,
+		"repo", info.repo,
+		"handler", handler,
+		"decision", decision,
+		"code", code.String(),
+		"reason", resp.Status.Message,
+		"duration", elapsed,
+	)
+}
@@ -0,0 +1,118 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+)
+
+func TestServer_Record(t *testing.T) {
+	body, err := os.ReadFile("testdata/push.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		header   map[string][]string
+		cfg      *pb.Config
+		fields   map[string]interface{}
+		decision string
+		code     codes.Code
+	}{
+		{
+			name:   "continue",
+			header: map[string][]string{"X-Github-Event": {"push"}, "X-Github-Delivery": {"72d3162e"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{}},
+			fields: map[string]interface{}{
+				"delivery": "72d3162e",
+				"scm":      "github",
+				"event":    "push",
+				"repo":     "Codertocat/Hello-World",
+				"handler":  "push",
+				"decision": DecisionContinue,
+				"code":     "OK",
+				"reason":   "",
+			},
+			decision: DecisionContinue,
+			code:     codes.OK,
+		},
+		{
+			name:   "deny",
+			header: map[string][]string{"X-Github-Event": {"push"}, "X-Github-Delivery": {"72d3162f"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{Ref: []string{"refs/heads/release-*"}}},
+			fields: map[string]interface{}{
+				"delivery": "72d3162f",
+				"scm":      "github",
+				"event":    "push",
+				"repo":     "Codertocat/Hello-World",
+				"handler":  "push",
+				"decision": DecisionDeny,
+				"code":     "FailedPrecondition",
+				"reason":   "did not find matching ref pattern",
+			},
+			decision: DecisionDeny,
+			code:     codes.FailedPrecondition,
+		},
+		{
+			name:   "unsupported event",
+			header: map[string][]string{"X-Github-Event": {"fork"}},
+			cfg:    &pb.Config{},
+			fields: map[string]interface{}{
+				"delivery": "",
+				"scm":      "github",
+				"event":    "fork",
+				"repo":     "",
+				"handler":  "none",
+				"decision": DecisionDeny,
+				"code":     "Unimplemented",
+				"reason":   "unsupported event type: [fork]",
+			},
+			decision: DecisionDeny,
+			code:     codes.Unimplemented,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			s := New(http.DefaultClient, nil, WithLogger(zap.New(core).Sugar()))
+
+			handler := tc.fields["handler"].(string)
+			counter := requests.WithLabelValues(handler, tc.decision, tc.code.String())
+			before := testutil.ToFloat64(counter)
+
+			b := new(bytes.Buffer)
+			if err := json.NewEncoder(b).Encode(&v1alpha1.InterceptorRequest{
+				Body:              string(body),
+				Header:            tc.header,
+				InterceptorParams: map[string]interface{}{"config": tc.cfg},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", b))
+
+			if got := testutil.ToFloat64(counter) - before; got != 1 {
+				t.Errorf("want request counted once, got %v", got)
+			}
+			entries := logs.All()
+			if len(entries) != 1 {
+				t.Fatalf("want 1 log entry, got %d", len(entries))
+			}
+			fields := entries[0].ContextMap()
+			delete(fields, "duration")
+			if diff := cmp.Diff(tc.fields, fields); diff != "" {
+				t.Errorf("-want +got: %s", diff)
+			}
+		})
+	}
+}
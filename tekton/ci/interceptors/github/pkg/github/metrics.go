@@ -0,0 +1,50 @@
+package github
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+
+	DecisionContinue = "continue"
+	DecisionDeny     = "deny"
+)
+
+var (
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_interceptor_requests_total",
+		Help: "Number of interceptor requests, by handler, decision (continue or deny) and status code.",
+	}, []string{"handler", "decision", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "github_interceptor_request_duration_seconds",
+		Help:    "Time taken to handle interceptor requests, by handler.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler"})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "github_interceptor_cache_requests_total",
+		Help: "Number of cacheable GitHub API requests, by result (hit if the cached response was still valid, miss otherwise).",
+	}, []string{"result"})
+
+	rateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "github_interceptor_rate_limit_remaining",
+		Help: "Number of GitHub API requests remaining in the current rate limit window, by resource, as of the last response.",
+	}, []string{"resource"})
+
+	rateLimitWait = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "github_interceptor_rate_limit_wait_seconds_total",
+		Help: "Time GitHub API requests were delayed until the rate limit reset.",
+	})
+
+	rateLimitRejections = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "github_interceptor_rate_limit_rejections_total",
+		Help: "Number of GitHub API requests failed because the rate limit would not reset in time.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(requests, requestDuration, cacheRequests, rateLimitRemaining, rateLimitWait, rateLimitRejections)
+}
@@ -0,0 +1,378 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/google/go-github/v34/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"google.golang.org/grpc/codes"
+	"sigs.k8s.io/yaml"
+)
+
+// approverSource configures who can approve a run.
+type approverSource struct {
+	// approvers is the root OWNERS file. Default: "OWNERS".
+	approvers *pb.File
+	// aliases is the OWNERS_ALIASES file. Default: "OWNERS_ALIASES" at the
+	// approvers revision.
+	aliases *pb.File
+	// changedFiles, if set, lists the files changed by the pull request.
+	// Approvers must then own each of them.
+	changedFiles func() ([]string, error)
+	// orgs and teams ("org/team-slug") whose members can also approve.
+	orgs  []string
+	teams []string
+}
+
+// repoAPI is the part of an SCM's API approvers are checked, and approval
+// labels set, with.
+type repoAPI interface {
+	// file returns the content of a file at a revision, or "" if it does
+	// not exist.
+	file(ctx context.Context, org, repo, path, revision string) (string, error)
+	orgMember(ctx context.Context, org, user string) (bool, error)
+	teamMember(ctx context.Context, org, slug, user string) (bool, error)
+	pullRequestFiles(ctx context.Context, org, repo string, number int) ([]string, error)
+	addLabel(ctx context.Context, org, repo string, number int, label string) error
+	// removeLabel removes a label from a pull request, if present.
+	removeLabel(ctx context.Context, org, repo string, number int, label string) error
+}
+
+// githubAPI is the repoAPI of GitHub.
+type githubAPI struct {
+	client *github.Client
+}
+
+func (a githubAPI) file(ctx context.Context, org, repo, path, revision string) (string, error) {
+	fc, _, _, err := a.client.Repositories.GetContents(ctx, org, repo, path, &github.RepositoryContentGetOptions{Ref: revision})
+	if isNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fc.GetContent()
+}
+
+func (a githubAPI) orgMember(ctx context.Context, org, user string) (bool, error) {
+	ok, _, err := a.client.Organizations.IsMember(ctx, org, user)
+	return ok, err
+}
+
+func (a githubAPI) teamMember(ctx context.Context, org, slug, user string) (bool, error) {
+	m, _, err := a.client.Teams.GetTeamMembershipBySlug(ctx, org, slug, user)
+	if isNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return m.GetState() == "active", nil
+}
+
+func (a githubAPI) pullRequestFiles(ctx context.Context, org, repo string, number int) ([]string, error) {
+	return pullRequestFiles(ctx, a.client, org, repo, number)
+}
+
+func (a githubAPI) addLabel(ctx context.Context, org, repo string, number int, label string) error {
+	_, _, err := a.client.Issues.AddLabelsToIssue(ctx, org, repo, number, []string{label})
+	return err
+}
+
+func (a githubAPI) removeLabel(ctx context.Context, org, repo string, number int, label string) error {
+	_, err := a.client.Issues.RemoveLabelForIssue(ctx, org, repo, number, label)
+	if isNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// commentApprovers returns the approvers configured for a pull request
+// comment.
+func commentApprovers(ctx context.Context, api repoAPI, org, repo string, number int, cfg *pb.PullRequestConfig_CommentConfig) approverSource {
+	src := approverSource{
+		approvers: cfg.GetApprovers(),
+		aliases:   cfg.GetAliases(),
+		orgs:      cfg.GetOrgs(),
+		teams:     cfg.GetTeams(),
+	}
+	if cfg.GetChangedFiles() {
+		src.changedFiles = func() ([]string, error) {
+			return api.pullRequestFiles(ctx, org, repo, number)
+		}
+	}
+	return src
+}
+
+// checkApprover verifies that user is allowed to approve runs by src: either
+// listed in its OWNERS files, or a member of one of its orgs or teams.
+func checkApprover(ctx context.Context, api repoAPI, org, repo string, src approverSource, user string) error {
+	ok, err := isMember(ctx, api, src.orgs, src.teams, user)
+	if err != nil {
+		return err
+	}
+	if ok {
+		return nil
+	}
+
+	r, err := newOwnersResolver(ctx, api, org, repo, src)
+	if err != nil {
+		return err
+	}
+	if src.changedFiles == nil {
+		ok, err = r.ownsRoot(user)
+	} else {
+		var files []string
+		files, err = src.changedFiles()
+		if errors.Is(err, errTruncated) {
+			// Files that could not be listed may not be owned by user.
+			return Errorf(codes.PermissionDenied, "too many changed files to check approval")
+		}
+		if err != nil {
+			return Errorf(codes.Unavailable, "error listing changed files: %v", err)
+		}
+		ok, err = r.ownsAll(user, files)
+	}
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return Errorf(codes.PermissionDenied, "user not allowed to approve trigger")
+	}
+	return nil
+}
+
+// isMember reports whether user is a member of any of the orgs or teams.
+func isMember(ctx context.Context, api repoAPI, orgs, teams []string, user string) (bool, error) {
+	for _, org := range orgs {
+		ok, err := api.orgMember(ctx, org, user)
+		if err != nil {
+			return false, Errorf(codes.Unavailable, "error checking membership of org %s: %v", org, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	for _, team := range teams {
+		org, slug, ok := strings.Cut(team, "/")
+		if !ok || org == "" || slug == "" {
+			return false, Errorf(codes.InvalidArgument, "team %q is not of the form org/team-slug", team)
+		}
+		ok, err := api.teamMember(ctx, org, slug, user)
+		if err != nil {
+			return false, Errorf(codes.Unavailable, "error checking membership of team %s: %v", team, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isNotFound(err error) bool {
+	var e *github.ErrorResponse
+	return errors.As(err, &e) && e.Response != nil && e.Response.StatusCode == http.StatusNotFound
+}
+
+// config is a fork of the prow OWNERS config file.
+// See https://pkg.go.dev/k8s.io/test-infra/prow/repoowners#Config
+type config struct {
+	Approvers []string `json:"approvers,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+	Options   struct {
+		// NoParentOwners stops the lookup of owners in parent directories.
+		NoParentOwners bool `json:"no_parent_owners,omitempty"`
+	} `json:"options,omitempty"`
+	// Filters maps regexps matching file paths, relative to the directory
+	// of the OWNERS file, to the owners of those files.
+	Filters map[string]filter `json:"filters,omitempty"`
+}
+
+type filter struct {
+	Approvers []string `json:"approvers,omitempty"`
+	Reviewers []string `json:"reviewers,omitempty"`
+}
+
+// aliases is the prow OWNERS_ALIASES file.
+type aliases struct {
+	Aliases map[string][]string `json:"aliases,omitempty"`
+}
+
+// loadSimpleConfig loads SimpleConfig from bytes `b`
+func loadConfig(b []byte) (config, error) {
+	simple := new(config)
+	err := yaml.Unmarshal(b, simple)
+	return *simple, err
+}
+
+// owns reports whether user owns file according to the config, resolving
+// aliases. file is relative to the directory of the OWNERS file. If file is
+// empty, only owners of all files match: the top level
+// owners, and those of the ".*" filter.
+func (c config) owns(user, file string, aliases map[string][]string) (bool, error) {
+	users := append(append([]string{}, c.Approvers...), c.Reviewers...)
+	for expr, f := range c.Filters {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return false, Errorf(codes.InvalidArgument, "invalid OWNERS filter %q: %v", expr, err)
+		}
+		if (file == "" && expr == ".*") || (file != "" && re.MatchString(file)) {
+			users = append(append(users, f.Approvers...), f.Reviewers...)
+		}
+	}
+	for _, u := range users {
+		// GitHub logins, and prow aliases, are case insensitive.
+		if members, ok := aliases[strings.ToLower(u)]; ok {
+			for _, m := range members {
+				if strings.EqualFold(m, user) {
+					return true, nil
+				}
+			}
+			continue
+		}
+		if strings.EqualFold(u, user) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func containsOwner(content, owner string) (bool, error) {
+	cfg, err := loadConfig([]byte(content))
+	if err != nil {
+		return false, err
+	}
+	return cfg.owns(owner, "", nil)
+}
+
+// ownersResolver looks up OWNERS files of a repo at a revision. Files are
+// fetched at most once.
+type ownersResolver struct {
+	ctx       context.Context
+	api       repoAPI
+	org, repo string
+	revision  string
+	rootPath  string
+	aliases   map[string][]string
+	configs   map[string]*config
+}
+
+func newOwnersResolver(ctx context.Context, api repoAPI, org, repo string, src approverSource) (*ownersResolver, error) {
+	r := &ownersResolver{
+		ctx:      ctx,
+		api:      api,
+		org:      org,
+		repo:     repo,
+		revision: src.approvers.GetRevision(),
+		rootPath: src.approvers.GetPath(),
+		aliases:  make(map[string][]string),
+		configs:  make(map[string]*config),
+	}
+	if r.rootPath == "" {
+		r.rootPath = "OWNERS"
+	}
+
+	aliasPath := src.aliases.GetPath()
+	if aliasPath == "" {
+		aliasPath = "OWNERS_ALIASES"
+	}
+	aliasRevision := src.aliases.GetRevision()
+	if aliasRevision == "" {
+		aliasRevision = r.revision
+	}
+	content, err := r.get(aliasPath, aliasRevision)
+	if err != nil {
+		return nil, err
+	}
+	if content != "" {
+		a := new(aliases)
+		if err := yaml.Unmarshal([]byte(content), a); err != nil {
+			return nil, Errorf(codes.InvalidArgument, "unable to read OWNERS_ALIASES file")
+		}
+		for name, members := range a.Aliases {
+			r.aliases[strings.ToLower(name)] = members
+		}
+	}
+	return r, nil
+}
+
+// get returns the content of a file, or "" if it does not exist.
+func (r *ownersResolver) get(file, revision string) (string, error) {
+	return r.api.file(r.ctx, r.org, r.repo, file, revision)
+}
+
+// load returns the OWNERS config at file, or nil if there is none.
+func (r *ownersResolver) load(file string) (*config, error) {
+	if cfg, ok := r.configs[file]; ok {
+		return cfg, nil
+	}
+	content, err := r.get(file, r.revision)
+	if err != nil {
+		return nil, err
+	}
+	var cfg *config
+	if content != "" {
+		c, err := loadConfig([]byte(content))
+		if err != nil {
+			return nil, Errorf(codes.InvalidArgument, "unable to read OWNERS file %s", file)
+		}
+		cfg = &c
+	}
+	r.configs[file] = cfg
+	return cfg, nil
+}
+
+// ownsRoot reports whether user is listed in the root OWNERS file, which
+// must exist.
+func (r *ownersResolver) ownsRoot(user string) (bool, error) {
+	cfg, err := r.load(r.rootPath)
+	if err != nil {
+		return false, err
+	}
+	if cfg == nil {
+		return false, Errorf(codes.NotFound, "OWNERS file %s not found", r.rootPath)
+	}
+	return cfg.owns(user, "", r.aliases)
+}
+
+// ownsAll reports whether user owns each of the files, as listed in the
+// OWNERS files of the file's directory or its parents.
+func (r *ownersResolver) ownsAll(user string, files []string) (bool, error) {
+	if len(files) == 0 {
+		return r.ownsRoot(user)
+	}
+	name := path.Base(r.rootPath)
+	for _, f := range files {
+		owned := false
+		for dir := path.Dir(f); ; dir = path.Dir(dir) {
+			cfg, err := r.load(path.Join(dir, name))
+			if err != nil {
+				return false, err
+			}
+			if cfg != nil {
+				rel := f
+				if dir != "." {
+					rel = strings.TrimPrefix(f, dir+"/")
+				}
+				if owned, err = cfg.owns(user, rel, r.aliases); err != nil {
+					return false, err
+				}
+				if owned || cfg.Options.NoParentOwners {
+					break
+				}
+			}
+			if dir == "." {
+				break
+			}
+		}
+		if !owned {
+			return false, nil
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,254 @@
+package github
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v34/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"google.golang.org/grpc/codes"
+)
+
+// fakeOwnersRepo serves the files of Codertocat/Hello-World at revision
+// "main", and the membership of the tektoncd org and its "core" team.
+func fakeOwnersRepo(t *testing.T, files map[string]string) *github.Client {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/Codertocat/Hello-World/contents/", func(rw http.ResponseWriter, r *http.Request) {
+		content, ok := files[strings.TrimPrefix(r.URL.Path, "/repos/Codertocat/Hello-World/contents/")]
+		if !ok || r.URL.Query().Get("ref") != "main" {
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(&github.RepositoryContent{
+			Type:     github.String("file"),
+			Encoding: github.String("base64"),
+			Content:  github.String(base64.StdEncoding.EncodeToString([]byte(content))),
+		})
+	})
+	mux.HandleFunc("/orgs/tektoncd/members/", func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/Member") {
+			rw.WriteHeader(http.StatusNoContent)
+			return
+		}
+		rw.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/orgs/tektoncd/teams/core/memberships/", func(rw http.ResponseWriter, r *http.Request) {
+		state := "active"
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/Invited"):
+			state = "pending"
+		case !strings.HasSuffix(r.URL.Path, "/Maintainer"):
+			rw.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(rw).Encode(&github.Membership{State: github.String(state)})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	client := github.NewClient(srv.Client())
+	client.BaseURL = mustParseURL(srv.URL + "/")
+	return client
+}
+
+func TestCheckApprover(t *testing.T) {
+	ctx := context.Background()
+	client := fakeOwnersRepo(t, map[string]string{
+		"OWNERS": `approvers:
+- root-approvers
+reviewers:
+- Alice
+filters:
+  ".*":
+    approvers:
+    - Everywhere
+  "\\.md$":
+    approvers:
+    - markdowner
+`,
+		"OWNERS_ALIASES": `aliases:
+  root-approvers:
+  - Codercat
+`,
+		"docs/OWNERS": `approvers:
+- docs-writer
+`,
+		"pkg/OWNERS": `filters:
+  "\\.go$":
+    approvers:
+    - gopher
+`,
+		"pkg/github/OWNERS": `filters:
+  "^testdata/":
+    approvers:
+    - fixture-owner
+`,
+		"vendor/OWNERS": `options:
+  no_parent_owners: true
+approvers:
+- vendorer
+`,
+		"custom/ALIASES": `aliases:
+  root-approvers:
+  - Someone
+`,
+	})
+	approvers := &pb.File{Revision: "main"}
+
+	for _, tc := range []struct {
+		name     string
+		src      approverSource
+		user     string
+		files    []string
+		filesErr error
+		code     codes.Code
+	}{
+		{
+			name: "root alias",
+			user: "codercat",
+		},
+		{
+			name: "root reviewer",
+			user: "Alice",
+		},
+		{
+			name: "root filter for all files",
+			user: "Everywhere",
+		},
+		{
+			name: "root filter for some files is not a root owner",
+			user: "markdowner",
+			code: codes.PermissionDenied,
+		},
+		{
+			name:  "root filter owner",
+			user:  "markdowner",
+			files: []string{"docs/README.md", "README.md"},
+		},
+		{
+			name: "alias name is not a user",
+			user: "root-approvers",
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "custom aliases file",
+			src:  approverSource{aliases: &pb.File{Path: "custom/ALIASES"}},
+			user: "Someone",
+		},
+		{
+			name: "directory owners are not root owners",
+			user: "docs-writer",
+			code: codes.PermissionDenied,
+		},
+		{
+			name:  "directory owner",
+			user:  "docs-writer",
+			files: []string{"docs/a/install.md", "docs/README.md"},
+		},
+		{
+			name:  "directory owner must own all files",
+			user:  "docs-writer",
+			files: []string{"docs/README.md", "main.go"},
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:  "parent owner",
+			user:  "Codercat",
+			files: []string{"docs/README.md", "main.go"},
+		},
+		{
+			name:  "no parent owners",
+			user:  "Codercat",
+			files: []string{"vendor/lib/lib.go"},
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:  "owner without parent owners",
+			user:  "vendorer",
+			files: []string{"vendor/lib/lib.go"},
+		},
+		{
+			name:  "matching filter",
+			user:  "gopher",
+			files: []string{"pkg/github/owners.go"},
+		},
+		{
+			name:  "non-matching filter",
+			user:  "gopher",
+			files: []string{"pkg/github/testdata/OWNERS"},
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:  "filter relative to the OWNERS directory",
+			user:  "fixture-owner",
+			files: []string{"pkg/github/testdata/OWNERS"},
+		},
+		{
+			name:  "filter relative to the OWNERS directory does not match elsewhere",
+			user:  "fixture-owner",
+			files: []string{"pkg/github/testdata/OWNERS", "pkg/testdata/OWNERS"},
+			code:  codes.PermissionDenied,
+		},
+		{
+			name:     "too many changed files",
+			user:     "Codercat",
+			files:    []string{},
+			filesErr: errTruncated,
+			code:     codes.PermissionDenied,
+		},
+		{
+			name: "org member",
+			src:  approverSource{orgs: []string{"tektoncd"}},
+			user: "Member",
+		},
+		{
+			name: "team member",
+			src:  approverSource{teams: []string{"tektoncd/core"}},
+			user: "Maintainer",
+		},
+		{
+			name: "pending team member",
+			src:  approverSource{teams: []string{"tektoncd/core"}},
+			user: "Invited",
+			code: codes.PermissionDenied,
+		},
+		{
+			name: "invalid team",
+			src:  approverSource{teams: []string{"core"}},
+			user: "Maintainer",
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "missing root OWNERS",
+			src:  approverSource{approvers: &pb.File{Revision: "main", Path: "MISSING"}},
+			user: "Codercat",
+			code: codes.NotFound,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src := tc.src
+			if src.approvers == nil {
+				src.approvers = approvers
+			}
+			if tc.files != nil {
+				src.changedFiles = func() ([]string, error) { return tc.files, tc.filesErr }
+			}
+			err := checkApprover(ctx, githubAPI{client}, "Codertocat", "Hello-World", src, tc.user)
+			if tc.code == codes.OK {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			serr := new(StatusError)
+			if !errors.As(err, serr) || serr.Code != tc.code {
+				t.Fatalf("want code %v, got %v", tc.code, err)
+			}
+		})
+	}
+}
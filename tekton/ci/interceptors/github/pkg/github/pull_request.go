@@ -3,8 +3,8 @@ package github
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 
-	"github.com/gobwas/glob"
 	"github.com/google/go-github/v34/github"
 	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
 	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
@@ -15,10 +15,9 @@ import (
 var (
 	_ = Interceptor(&PullRequest{})
 
-	actions = map[string]bool{
-		"opened":       true,
-		"synchronized": true,
-	}
+	// defaultActions are the pull request actions that run if none are
+	// configured.
+	defaultActions = []string{"opened", "synchronize", "reopened"}
 )
 
 type PullRequest struct{}
@@ -33,48 +32,154 @@ func (c *PullRequest) Execute(ctx context.Context, client *github.Client, cfg *p
 		return nil, Error(codes.FailedPrecondition, "trigger not configured for pull_request")
 	}
 
-	if _, ok := actions[event.GetAction()]; !ok {
-		return nil, Error(codes.Unimplemented, "unsupported action")
+	prCfg := cfg.GetPullRequest()
+	if !containsString(actionsOrDefault(prCfg), event.GetAction()) {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", event.GetAction())
 	}
 
-	prCfg := cfg.GetPullRequest()
-	if prCfg.GetComment() != nil {
-		return nil, Error(codes.FailedPrecondition, "waiting for authorized approval")
+	if prCfg.GetSkipDrafts() && event.GetPullRequest().GetDraft() {
+		return nil, Error(codes.FailedPrecondition, "skipping draft pull request")
+	}
+
+	if err := checkLabels(prCfg, labelNames(event.GetPullRequest().Labels)); err != nil {
+		return nil, err
 	}
 
+	pr := event.GetPullRequest()
+	owner := pr.GetBase().GetRepo().GetOwner().GetLogin()
+	repo := pr.GetBase().GetRepo().GetName()
 	patterns := prCfg.GetBranch()
 	if patterns == nil {
 		// Default to all branches
 		patterns = []string{"**"}
 	}
-	for _, pattern := range patterns {
-		g, err := glob.Compile(pattern)
-		if err != nil {
-			continue
+	if !matchGlob(patterns, pr.GetBase().GetRef()) {
+		return &v1alpha1.InterceptorResponse{
+			Continue: false,
+			Status: v1alpha1.Status{
+				Code:    codes.FailedPrecondition,
+				Message: "did not find matching branch pattern",
+			},
+		}, nil
+	}
+
+	git, gh := pullRequestBindings(pr, event.GetInstallation().GetID())
+	ext := map[string]interface{}{
+		"git":    git,
+		"github": gh,
+	}
+
+	files, resp, err := filterPaths(prCfg.GetPaths(), func() ([]string, error) {
+		return pullRequestFiles(ctx, client, owner, repo, pr.GetNumber())
+	})
+	if resp != nil || err != nil {
+		return resp, err
+	}
+	if files != nil {
+		ext["changed_files"] = files
+	}
+
+	// Trust is checked last, so that the approval label is only removed
+	// from pull requests the trigger would run for.
+	if commentCfg := prCfg.GetComment(); commentCfg != nil {
+		p := trustedPush{
+			org:         owner,
+			repo:        repo,
+			number:      pr.GetNumber(),
+			author:      pr.GetUser().GetLogin(),
+			association: pr.GetAuthorAssociation(),
+			labels:      labelNames(pr.Labels),
+		}
+		if event.GetAction() == "synchronize" {
+			p.pusher = event.GetSender().GetLogin()
 		}
-		pr := event.GetPullRequest()
-		if g.Match(pr.GetBase().GetRef()) {
-			return &v1alpha1.InterceptorResponse{
-				Continue: true,
-				Extensions: map[string]interface{}{
-					"git": bindings.Git{
-						URL:      pr.GetHead().GetRepo().GetCloneURL(),
-						Revision: pr.GetHead().GetSHA(),
-					},
-					"github": bindings.GitHub{
-						Owner:        pr.GetBase().GetRepo().GetOwner().GetLogin(),
-						Repo:         pr.GetBase().GetRepo().GetName(),
-						Installation: event.GetInstallation().GetID(),
-					},
-				},
-			}, nil
+		if err := checkTrusted(ctx, githubAPI{client}, commentCfg, p); err != nil {
+			return nil, err
 		}
 	}
+
 	return &v1alpha1.InterceptorResponse{
-		Continue: false,
-		Status: v1alpha1.Status{
-			Code:    codes.FailedPrecondition,
-			Message: "did not find matching branch pattern",
-		},
+		Continue:   true,
+		Extensions: ext,
 	}, nil
 }
+
+// pullRequestBindings returns the git and github extensions of a pull
+// request.
+func pullRequestBindings(pr *github.PullRequest, installation int64) (bindings.Git, bindings.GitHub) {
+	labels := labelNames(pr.Labels)
+	// Fetch the base commit too, so that changes can be diffed against it.
+	depth := 0
+	if commits := pr.GetCommits(); commits > 0 {
+		depth = commits + 1
+	}
+	ref := fmt.Sprintf("refs/pull/%d", pr.GetNumber())
+	git := bindings.Git{
+		URL:      pr.GetHead().GetRepo().GetCloneURL(),
+		Revision: pr.GetHead().GetSHA(),
+		Ref:      ref + "/head",
+		Depth:    depth,
+	}
+	gh := bindings.GitHub{
+		Owner:        pr.GetBase().GetRepo().GetOwner().GetLogin(),
+		Repo:         pr.GetBase().GetRepo().GetName(),
+		Installation: installation,
+		PullRequest: &bindings.PullRequest{
+			Number:   pr.GetNumber(),
+			Author:   pr.GetUser().GetLogin(),
+			BaseURL:  pr.GetBase().GetRepo().GetCloneURL(),
+			BaseRef:  pr.GetBase().GetRef(),
+			BaseSHA:  pr.GetBase().GetSHA(),
+			HeadRef:  pr.GetHead().GetRef(),
+			HeadSHA:  pr.GetHead().GetSHA(),
+			Labels:   labels,
+			Draft:    pr.GetDraft(),
+			Commits:  pr.GetCommits(),
+			PullRef:  ref + "/head",
+			MergeRef: ref + "/merge",
+		},
+	}
+	return git, gh
+}
+
+// actionsOrDefault returns the pull request actions to run on.
+func actionsOrDefault(cfg *pb.PullRequestConfig) []string {
+	if a := cfg.GetActions(); len(a) > 0 {
+		return a
+	}
+	return defaultActions
+}
+
+// labelNames returns the names of labels, never nil.
+func labelNames(labels []*github.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.GetName())
+	}
+	return names
+}
+
+// checkLabels verifies that a pull request has all required labels and
+// none of the forbidden ones.
+func checkLabels(cfg *pb.PullRequestConfig, names []string) error {
+	for _, l := range cfg.GetRequiredLabels() {
+		if !containsString(names, l) {
+			return Errorf(codes.FailedPrecondition, "missing required label %q", l)
+		}
+	}
+	for _, l := range cfg.GetForbiddenLabels() {
+		if containsString(names, l) {
+			return Errorf(codes.FailedPrecondition, "forbidden label %q present", l)
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/google/go-github/v34/github"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	_ = Interceptor(&PullRequestReview{})
+)
+
+// PullRequestReview runs pull requests when a review is submitted.
+type PullRequestReview struct{}
+
+func (c *PullRequestReview) Execute(ctx context.Context, client *github.Client, cfg *pb.Config, req *v1alpha1.InterceptorRequest) (*v1alpha1.InterceptorResponse, error) {
+	event := new(github.PullRequestReviewEvent)
+	if err := json.Unmarshal([]byte(req.Body), event); err != nil {
+		return nil, Error(codes.InvalidArgument, err.Error())
+	}
+
+	reviewCfg := cfg.GetPullRequestReview()
+	if reviewCfg == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for pull_request_review")
+	}
+
+	if event.GetAction() != "submitted" {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", event.GetAction())
+	}
+
+	states := reviewCfg.GetState()
+	if states == nil {
+		states = []string{"approved"}
+	}
+	// The webhook reports states in lower case, the REST API in upper case.
+	state := strings.ToLower(event.GetReview().GetState())
+	if !containsString(states, state) {
+		return nil, Errorf(codes.FailedPrecondition, "review state %q does not match", state)
+	}
+
+	patterns := reviewCfg.GetBranch()
+	if patterns == nil {
+		// Default to all branches
+		patterns = []string{"**"}
+	}
+	pr := event.GetPullRequest()
+	if !matchGlob(patterns, pr.GetBase().GetRef()) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching branch pattern")
+	}
+
+	owner := pr.GetBase().GetRepo().GetOwner().GetLogin()
+	repo := pr.GetBase().GetRepo().GetName()
+	// Anyone can review a pull request, so the reviewer must always be an
+	// approver.
+	if err := checkApprover(ctx, githubAPI{client}, owner, repo, approverSource{approvers: reviewCfg.GetApprovers()}, event.GetReview().GetUser().GetLogin()); err != nil {
+		return nil, err
+	}
+
+	git, gh := pullRequestBindings(pr, event.GetInstallation().GetID())
+	return &v1alpha1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			"pull_request": pr,
+			"review":       event.GetReview(),
+			"git":          git,
+			"github":       gh,
+		},
+	}, nil
+}
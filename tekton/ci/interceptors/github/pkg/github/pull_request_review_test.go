@@ -0,0 +1,171 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v34/github"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestExecute_PullRequestReview(t *testing.T) {
+	ctx := context.Background()
+	h := &PullRequestReview{}
+
+	// Setup fake GitHub client.
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	client := github.NewClient(srv.Client())
+	client.BaseURL = mustParseURL(srv.URL + "/")
+	mux.HandleFunc("/repos/Codertocat/Hello-World/contents/OWNERS", func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"type":    "file",
+			"content": "approvers:\n- Codertocat\n",
+		})
+	})
+	mux.HandleFunc("/repos/Codertocat/Hello-World/contents/TESTOWNERS", func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"type":    "file",
+			"content": ownersFile,
+		})
+	})
+
+	f, err := os.ReadFile("testdata/pull_request_review.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &v1alpha1.InterceptorRequest{
+		Body: string(f),
+		Header: map[string][]string{
+			"X-Github-Event": {"pull_request_review"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		cfg      *pb.Config
+		reviewer string
+		ok       bool
+	}{
+		{
+			name: "default",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{},
+			},
+			ok: true,
+		},
+		{
+			name: "non-matching state",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{
+					State: []string{"commented"},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "non-matching branch",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{
+					Branch: []string{"release-*"},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "reviewer is an approver",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{
+					Approvers: &pb.File{},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "reviewer is not an approver",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{
+					Approvers: &pb.File{
+						Path: "TESTOWNERS",
+					},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "reviewer is not a default approver",
+			cfg: &pb.Config{
+				PullRequestReview: &pb.PullRequestReviewConfig{},
+			},
+			reviewer: "Outsider",
+			ok:       false,
+		},
+		{
+			name: "no config",
+			cfg:  &pb.Config{},
+			ok:   false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := req
+			if tc.reviewer != "" {
+				event := new(github.PullRequestReviewEvent)
+				if err := json.Unmarshal(f, event); err != nil {
+					t.Fatal(err)
+				}
+				event.Review.User.Login = github.String(tc.reviewer)
+				body, err := json.Marshal(event)
+				if err != nil {
+					t.Fatal(err)
+				}
+				req = &v1alpha1.InterceptorRequest{Body: string(body), Header: req.Header}
+			}
+			resp, err := h.Execute(ctx, client, tc.cfg, req)
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected failure, got (%+v, %v)", resp, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success: %t, got (%+v, %v)", tc.ok, resp, err)
+			}
+
+			if diff := cmp.Diff(bindings.Git{
+				URL:      "https://github.com/Codertocat/Hello-World.git",
+				Revision: "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+				Ref:      "refs/pull/2/head",
+				Depth:    2,
+			}, resp.Extensions["git"]); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(bindings.GitHub{
+				Owner: "Codertocat",
+				Repo:  "Hello-World",
+				PullRequest: &bindings.PullRequest{
+					Number:   2,
+					Author:   "Codertocat",
+					BaseURL:  "https://github.com/Codertocat/Hello-World.git",
+					BaseRef:  "master",
+					BaseSHA:  "f95f852bd8fca8fcc58a9a2d6c842781e32a215e",
+					HeadRef:  "changes",
+					HeadSHA:  "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+					Labels:   []string{},
+					Commits:  1,
+					PullRef:  "refs/pull/2/head",
+					MergeRef: "refs/pull/2/merge",
+				},
+			}, resp.Extensions["github"]); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
@@ -4,14 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"log"
+	"net/http"
+	"path"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v34/github"
 	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
 	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
 )
 
 func TestExecute_PullRequest(t *testing.T) {
@@ -100,10 +107,25 @@ func TestExecute_PullRequest(t *testing.T) {
 					"git": bindings.Git{
 						URL:      "https://github.com/Codertocat/Hello-World.git",
 						Revision: "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+						Ref:      "refs/pull/2/head",
+						Depth:    2,
 					},
 					"github": bindings.GitHub{
 						Owner: "Codertocat",
 						Repo:  "Hello-World",
+						PullRequest: &bindings.PullRequest{
+							Number:   2,
+							Author:   "Codertocat",
+							BaseURL:  "https://github.com/Codertocat/Hello-World.git",
+							BaseRef:  "master",
+							BaseSHA:  "f95f852bd8fca8fcc58a9a2d6c842781e32a215e",
+							HeadRef:  "changes",
+							HeadSHA:  "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+							Labels:   []string{},
+							Commits:  1,
+							PullRef:  "refs/pull/2/head",
+							MergeRef: "refs/pull/2/merge",
+						},
 					},
 				},
 			}
@@ -113,3 +135,371 @@ func TestExecute_PullRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestExecute_PullRequestFilters(t *testing.T) {
+	ctx := context.Background()
+	h := &PullRequest{}
+
+	f, err := os.ReadFile("testdata/pull_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		cfg    *pb.PullRequestConfig
+		action string
+		draft  bool
+		labels []string
+		ok     bool
+	}{
+		{
+			name:   "synchronize runs by default",
+			cfg:    &pb.PullRequestConfig{},
+			action: "synchronize",
+			ok:     true,
+		},
+		{
+			name:   "reopened runs by default",
+			cfg:    &pb.PullRequestConfig{},
+			action: "reopened",
+			ok:     true,
+		},
+		{
+			name:   "labeled does not run by default",
+			cfg:    &pb.PullRequestConfig{},
+			action: "labeled",
+			ok:     false,
+		},
+		{
+			name:   "configured action",
+			cfg:    &pb.PullRequestConfig{Actions: []string{"labeled", "ready_for_review"}},
+			action: "ready_for_review",
+			ok:     true,
+		},
+		{
+			name:   "action not configured",
+			cfg:    &pb.PullRequestConfig{Actions: []string{"labeled"}},
+			action: "opened",
+			ok:     false,
+		},
+		{
+			name:   "draft runs by default",
+			cfg:    &pb.PullRequestConfig{},
+			action: "opened",
+			draft:  true,
+			ok:     true,
+		},
+		{
+			name:   "skip drafts",
+			cfg:    &pb.PullRequestConfig{SkipDrafts: true},
+			action: "opened",
+			draft:  true,
+			ok:     false,
+		},
+		{
+			name:   "required labels present",
+			cfg:    &pb.PullRequestConfig{RequiredLabels: []string{"ok-to-test", "lgtm"}},
+			action: "opened",
+			labels: []string{"lgtm", "ok-to-test", "kind/bug"},
+			ok:     true,
+		},
+		{
+			name:   "required label missing",
+			cfg:    &pb.PullRequestConfig{RequiredLabels: []string{"ok-to-test", "lgtm"}},
+			action: "opened",
+			labels: []string{"ok-to-test"},
+			ok:     false,
+		},
+		{
+			name:   "forbidden label present",
+			cfg:    &pb.PullRequestConfig{ForbiddenLabels: []string{"do-not-merge/hold"}},
+			action: "opened",
+			labels: []string{"do-not-merge/hold"},
+			ok:     false,
+		},
+		{
+			name:   "forbidden label absent",
+			cfg:    &pb.PullRequestConfig{ForbiddenLabels: []string{"do-not-merge/hold"}},
+			action: "opened",
+			labels: []string{"lgtm"},
+			ok:     true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event := new(github.PullRequestEvent)
+			if err := json.Unmarshal(f, event); err != nil {
+				t.Fatal(err)
+			}
+			event.Action = github.String(tc.action)
+			event.PullRequest.Draft = github.Bool(tc.draft)
+			event.PullRequest.Labels = nil
+			for _, l := range tc.labels {
+				event.PullRequest.Labels = append(event.PullRequest.Labels, &github.Label{Name: github.String(l)})
+			}
+			body, err := json.Marshal(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &v1alpha1.InterceptorRequest{
+				Body: string(body),
+				Header: map[string][]string{
+					"X-Github-Event": {"pull_request"},
+				},
+			}
+
+			resp, err := h.Execute(ctx, nil, &pb.Config{PullRequest: tc.cfg}, req)
+			if tc.ok && (err != nil || !resp.Continue) {
+				t.Fatalf("expected success, got (%+v, %v)", resp, err)
+			}
+			if !tc.ok && err == nil {
+				t.Fatalf("expected failure, got (%+v, %v)", resp, err)
+			}
+		})
+	}
+}
+
+func TestExecute_PullRequestTrust(t *testing.T) {
+	ctx := context.Background()
+	h := &PullRequest{}
+	owners := fakeOwnersRepo(t, map[string]string{
+		"OWNERS": `approvers:
+- Approver
+`,
+	})
+	// Record removed labels.
+	var removed []string
+	client := github.NewClient(&http.Client{Transport: roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodDelete {
+			removed = append(removed, path.Base(r.URL.Path))
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: r}, nil
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})})
+	client.BaseURL = owners.BaseURL
+
+	f, err := os.ReadFile("testdata/pull_request.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trusted := &pb.PullRequestConfig_CommentConfig{
+		Approvers:    &pb.File{Revision: "main"},
+		Orgs:         []string{"tektoncd"},
+		TrustAuthors: true,
+	}
+	for _, tc := range []struct {
+		name        string
+		cfg         *pb.PullRequestConfig_CommentConfig
+		action      string
+		author      string
+		association string
+		sender      string
+		labels      []string
+		branch      []string
+		// message of the expected error, none if empty.
+		message string
+		removed []string
+	}{
+		{
+			name:        "trust disabled",
+			cfg:         &pb.PullRequestConfig_CommentConfig{Approvers: &pb.File{Revision: "main"}},
+			author:      "Approver",
+			association: "OWNER",
+			message:     "waiting for authorized approval",
+		},
+		{
+			name:        "trusted association",
+			cfg:         trusted,
+			author:      "Codertocat",
+			association: "MEMBER",
+		},
+		{
+			name:        "approver",
+			cfg:         trusted,
+			author:      "Approver",
+			association: "NONE",
+		},
+		{
+			name:        "org member",
+			cfg:         trusted,
+			author:      "Member",
+			association: "CONTRIBUTOR",
+		},
+		{
+			name:        "first time contributor",
+			cfg:         trusted,
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			message:     "waiting for authorized approval of pull request by Outsider",
+		},
+		{
+			name: "configured association",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers:           &pb.File{Revision: "main"},
+				TrustAuthors:        true,
+				TrustedAssociations: []string{"CONTRIBUTOR"},
+			},
+			author:      "Contributor",
+			association: "CONTRIBUTOR",
+		},
+		{
+			name: "default associations are replaced",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers:           &pb.File{Revision: "main"},
+				TrustAuthors:        true,
+				TrustedAssociations: []string{"CONTRIBUTOR"},
+			},
+			author:      "Collaborator",
+			association: "COLLABORATOR",
+			message:     "waiting for authorized approval of pull request by Collaborator",
+		},
+		{
+			name:        "push by author",
+			cfg:         trusted,
+			action:      "synchronize",
+			author:      "Codertocat",
+			association: "MEMBER",
+			sender:      "Codertocat",
+		},
+		{
+			name:        "push by approver",
+			cfg:         trusted,
+			action:      "synchronize",
+			author:      "Codertocat",
+			association: "MEMBER",
+			sender:      "Approver",
+		},
+		{
+			name:        "push by untrusted user",
+			cfg:         trusted,
+			action:      "synchronize",
+			author:      "Codertocat",
+			association: "MEMBER",
+			sender:      "Outsider",
+			message:     "waiting for authorized approval of push by Outsider",
+		},
+		{
+			name:        "push to untrusted pull request",
+			cfg:         trusted,
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "CONTRIBUTOR",
+			sender:      "Outsider",
+			message:     "waiting for authorized approval of pull request by Outsider",
+		},
+		{
+			name: "approval label",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers: &pb.File{Revision: "main"},
+				Label:     "ok-to-test",
+			},
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			sender:      "Outsider",
+			labels:      []string{"ok-to-test"},
+		},
+		{
+			name: "approval label missing",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers: &pb.File{Revision: "main"},
+				Label:     "ok-to-test",
+			},
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			sender:      "Outsider",
+			labels:      []string{"lgtm"},
+			message:     "waiting for authorized approval",
+		},
+		{
+			name: "approval label, push by untrusted user",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers: &pb.File{Revision: "main"},
+				Label:     "ok-to-test",
+			},
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			sender:      "Stranger",
+			labels:      []string{"ok-to-test"},
+			message:     "waiting for authorized approval of push by Stranger",
+			removed:     []string{"ok-to-test"},
+		},
+		{
+			name: "approval label, push by untrusted user to other branch",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers: &pb.File{Revision: "main"},
+				Label:     "ok-to-test",
+			},
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			sender:      "Stranger",
+			labels:      []string{"ok-to-test"},
+			branch:      []string{"release-*"},
+			message:     "did not find matching branch pattern",
+		},
+		{
+			name: "approval label, push by approver",
+			cfg: &pb.PullRequestConfig_CommentConfig{
+				Approvers: &pb.File{Revision: "main"},
+				Label:     "ok-to-test",
+			},
+			action:      "synchronize",
+			author:      "Outsider",
+			association: "FIRST_TIME_CONTRIBUTOR",
+			sender:      "Approver",
+			labels:      []string{"ok-to-test"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			event := new(github.PullRequestEvent)
+			if err := json.Unmarshal(f, event); err != nil {
+				t.Fatal(err)
+			}
+			if tc.action != "" {
+				event.Action = github.String(tc.action)
+			}
+			event.PullRequest.User.Login = github.String(tc.author)
+			event.PullRequest.AuthorAssociation = github.String(tc.association)
+			event.Sender.Login = github.String(tc.sender)
+			event.PullRequest.Labels = nil
+			for _, l := range tc.labels {
+				event.PullRequest.Labels = append(event.PullRequest.Labels, &github.Label{Name: github.String(l)})
+			}
+			removed = nil
+			body, err := json.Marshal(event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &v1alpha1.InterceptorRequest{
+				Body: string(body),
+				Header: map[string][]string{
+					"X-Github-Event": {"pull_request"},
+				},
+			}
+
+			resp, err := h.Execute(ctx, client, &pb.Config{PullRequest: &pb.PullRequestConfig{Comment: tc.cfg, Branch: tc.branch}}, req)
+			if diff := cmp.Diff(tc.removed, removed); diff != "" {
+				t.Errorf("removed labels -want +got: %s", diff)
+			}
+			if tc.message == "" {
+				if err != nil || !resp.Continue {
+					t.Fatalf("expected success, got (%+v, %v)", resp, err)
+				}
+				return
+			}
+			serr := new(StatusError)
+			if err == nil && resp != nil && !resp.Continue {
+				serr.Status = resp.Status
+			} else if !errors.As(err, serr) {
+				t.Fatalf("expected status error, got (%+v, %v)", resp, err)
+			}
+			if serr.Code != codes.FailedPrecondition || serr.Message != tc.message {
+				t.Errorf("want %v %q, got %v %q", codes.FailedPrecondition, tc.message, serr.Code, serr.Message)
+			}
+		})
+	}
+}
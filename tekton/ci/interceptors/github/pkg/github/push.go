@@ -28,7 +28,7 @@ func (c *Push) Execute(ctx context.Context, client *github.Client, cfg *pb.Confi
 		return nil, Error(codes.FailedPrecondition, "trigger not configured for push")
 	}
 
-	if event.GetAfter() == "0000000000000000000000000000000000000000" {
+	if event.GetAfter() == zeroSHA {
 		return nil, Error(codes.FailedPrecondition, "ref was deleted - nothing to do")
 	}
 
@@ -37,27 +37,54 @@ func (c *Push) Execute(ctx context.Context, client *github.Client, cfg *pb.Confi
 		// Default to all branches and tags.
 		patterns = []string{"refs/heads/*", "refs/tags/*"}
 	}
+	if !matchGlob(patterns, event.GetRef()) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching ref pattern")
+	}
+
+	owner := event.GetRepo().GetOwner().GetLogin()
+	repo := event.GetRepo().GetName()
+	ext := map[string]interface{}{
+		"git": bindings.Git{
+			URL:      event.GetRepo().GetCloneURL(),
+			Revision: event.GetAfter(),
+			Ref:      event.GetRef(),
+		},
+		"github": bindings.GitHub{
+			Owner:        owner,
+			Repo:         repo,
+			Installation: event.GetInstallation().GetID(),
+		},
+	}
+
+	// New refs have nothing to compare against, so they always run.
+	if event.GetBefore() != zeroSHA {
+		files, resp, err := filterPaths(cfg.GetPush().GetPaths(), func() ([]string, error) {
+			return pushFiles(ctx, client, owner, repo, event.GetBefore(), event.GetAfter())
+		})
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		if files != nil {
+			ext["changed_files"] = files
+		}
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue:   true,
+		Extensions: ext,
+	}, nil
+}
+
+// matchGlob reports whether s matches any of the glob patterns.
+func matchGlob(patterns []string, s string) bool {
 	for _, pattern := range patterns {
 		g, err := glob.Compile(pattern)
 		if err != nil {
 			continue
 		}
-		if g.Match(event.GetRef()) {
-			return &v1alpha1.InterceptorResponse{
-				Continue: true,
-				Extensions: map[string]interface{}{
-					"git": bindings.Git{
-						URL:      event.GetRepo().GetCloneURL(),
-						Revision: event.GetAfter(),
-					},
-					"github": bindings.GitHub{
-						Owner:        event.GetRepo().GetOwner().GetLogin(),
-						Repo:         event.GetRepo().GetName(),
-						Installation: event.GetInstallation().GetID(),
-					},
-				},
-			}, nil
+		if g.Match(s) {
+			return true
 		}
 	}
-	return nil, Error(codes.FailedPrecondition, "did not find matching ref pattern")
+	return false
 }
@@ -108,6 +108,7 @@ func TestExecute_Push(t *testing.T) {
 					"git": bindings.Git{
 						URL:      "https://github.com/Codertocat/Hello-World.git",
 						Revision: "6113728f27ae82c7b1a177c8d03f9e96e0adf246",
+						Ref:      "refs/tags/simple-tag",
 					},
 					"github": bindings.GitHub{
 						Owner: "Codertocat",
@@ -0,0 +1,65 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/go-github/v34/github"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	_ = Interceptor(&Release{})
+)
+
+// Release runs when a release is published.
+type Release struct{}
+
+func (c *Release) Execute(ctx context.Context, client *github.Client, cfg *pb.Config, req *v1alpha1.InterceptorRequest) (*v1alpha1.InterceptorResponse, error) {
+	event := new(github.ReleaseEvent)
+	if err := json.Unmarshal([]byte(req.Body), event); err != nil {
+		return nil, Error(codes.InvalidArgument, err.Error())
+	}
+
+	if cfg.GetRelease() == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for release")
+	}
+
+	if event.GetAction() != "published" {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", event.GetAction())
+	}
+
+	release := event.GetRelease()
+	if release.GetPrerelease() && !cfg.GetRelease().GetIncludePrereleases() {
+		return nil, Error(codes.FailedPrecondition, "skipping prerelease")
+	}
+
+	patterns := cfg.GetRelease().GetTag()
+	if patterns == nil {
+		// Default to all tags.
+		patterns = []string{"**"}
+	}
+	if !matchGlob(patterns, release.GetTagName()) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching tag pattern")
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue: true,
+		Extensions: map[string]interface{}{
+			"release": release,
+			"git": bindings.Git{
+				URL:      event.GetRepo().GetCloneURL(),
+				Revision: "refs/tags/" + release.GetTagName(),
+				Ref:      "refs/tags/" + release.GetTagName(),
+			},
+			"github": bindings.GitHub{
+				Owner:        event.GetRepo().GetOwner().GetLogin(),
+				Repo:         event.GetRepo().GetName(),
+				Installation: event.GetInstallation().GetID(),
+			},
+		},
+	}, nil
+}
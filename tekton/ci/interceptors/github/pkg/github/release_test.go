@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v34/github"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+)
+
+func TestExecute_Release(t *testing.T) {
+	ctx := context.Background()
+	h := &Release{}
+
+	f, err := os.ReadFile("testdata/release.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prerelease := new(github.ReleaseEvent)
+	if err := json.Unmarshal(f, prerelease); err != nil {
+		t.Fatal(err)
+	}
+	prerelease.Release.Prerelease = github.Bool(true)
+	pf, err := json.Marshal(prerelease)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		body []byte
+		cfg  *pb.Config
+		ok   bool
+	}{
+		{
+			name: "default",
+			body: f,
+			cfg: &pb.Config{
+				Release: &pb.ReleaseConfig{},
+			},
+			ok: true,
+		},
+		{
+			name: "matching tag",
+			body: f,
+			cfg: &pb.Config{
+				Release: &pb.ReleaseConfig{
+					Tag: []string{"v*"},
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "non-matching tag",
+			body: f,
+			cfg: &pb.Config{
+				Release: &pb.ReleaseConfig{
+					Tag: []string{"release-*"},
+				},
+			},
+			ok: false,
+		},
+		{
+			name: "prerelease skipped by default",
+			body: pf,
+			cfg: &pb.Config{
+				Release: &pb.ReleaseConfig{},
+			},
+			ok: false,
+		},
+		{
+			name: "prerelease included",
+			body: pf,
+			cfg: &pb.Config{
+				Release: &pb.ReleaseConfig{
+					IncludePrereleases: true,
+				},
+			},
+			ok: true,
+		},
+		{
+			name: "no config",
+			body: f,
+			cfg:  &pb.Config{},
+			ok:   false,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := &v1alpha1.InterceptorRequest{
+				Body: string(tc.body),
+				Header: map[string][]string{
+					"X-Github-Event": {"release"},
+				},
+			}
+			resp, err := h.Execute(ctx, nil, tc.cfg, req)
+			if !tc.ok {
+				if err == nil {
+					t.Fatalf("expected failure, got (%+v, %v)", resp, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected success: %t, got (%+v, %v)", tc.ok, resp, err)
+			}
+
+			if diff := cmp.Diff(bindings.Git{
+				URL:      "https://github.com/Codertocat/Hello-World.git",
+				Revision: "refs/tags/v0.1.0",
+				Ref:      "refs/tags/v0.1.0",
+			}, resp.Extensions["git"]); diff != "" {
+				t.Error(diff)
+			}
+			if diff := cmp.Diff(bindings.GitHub{
+				Owner: "Codertocat",
+				Repo:  "Hello-World",
+			}, resp.Extensions["github"]); diff != "" {
+				t.Error(diff)
+			}
+		})
+	}
+}
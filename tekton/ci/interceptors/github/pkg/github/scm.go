@@ -0,0 +1,569 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/jenkins-x/go-scm/scm/factory"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+// WithSCMServer handles the events of an SCM other than GitHub, e.g. a
+// self-hosted Gitea. driver is the go-scm driver of the SCM: "gitea",
+// "gitlab", "bitbucket" (Bitbucket Cloud) or "stash" (Bitbucket Server).
+// serverURL is the base URL of the SCM, which may be empty for gitlab.com and
+// bitbucket.org, and token the token its API is called with.
+func WithSCMServer(driver, serverURL, token string) Option {
+	return func(s *Server) {
+		if s.scms == nil {
+			s.scms = make(map[string]*scmServer)
+		}
+		s.scms[driver] = &scmServer{driver: driver, url: serverURL, token: token}
+	}
+}
+
+// scmServer creates the go-scm client of an SCM on first use, since the
+// Gitea client contacts the server when it is created.
+type scmServer struct {
+	driver string
+	url    string
+	token  string
+
+	mu     sync.Mutex
+	client *scm.Client
+}
+
+func (s *scmServer) get() (*scm.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil {
+		return s.client, nil
+	}
+	c, err := factory.NewClient(s.driver, s.url, s.token)
+	if err != nil {
+		return nil, err
+	}
+	s.client = c
+	return c, nil
+}
+
+// detectDriver returns the go-scm driver of the SCM that sent a webhook, or
+// "" if unknown. Gitea also sends GitHub's headers, so it is detected first.
+func detectDriver(h http.Header) string {
+	switch {
+	case h.Get("X-Gitea-Event") != "":
+		return "gitea"
+	case h.Get("X-Gitlab-Event") != "":
+		return "gitlab"
+	case h.Get("X-Event-Key") != "" && h.Get("X-Hook-UUID") != "":
+		return "bitbucket"
+	case h.Get("X-Event-Key") != "":
+		return "stash"
+	case h.Get("X-Github-Event") != "":
+		return "github"
+	}
+	return ""
+}
+
+// handleSCM handles the events of SCMs other than GitHub. Push, pull request
+// and pull request comment events are supported, with the same config and
+// bindings as their GitHub counterparts.
+func (s *Server) handleSCM(ctx context.Context, driver string, in *v1alpha1.InterceptorRequest, info *requestInfo) (*v1alpha1.InterceptorResponse, error) {
+	srv, ok := s.scms[driver]
+	if !ok {
+		return nil, Errorf(codes.Unimplemented, "unsupported SCM: %s", driver)
+	}
+
+	cfg, err := Unmarshal(in.InterceptorParams)
+	if err != nil {
+		return nil, Errorf(codes.InvalidArgument, "error reading config: %v", err)
+	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	client, err := srv.get()
+	if err != nil {
+		return nil, Errorf(codes.Unavailable, "error creating %s client: %v", driver, err)
+	}
+	hook, err := s.parseWebhook(client, in)
+	if err != nil {
+		return nil, err
+	}
+	info.event = string(hook.Kind())
+	info.repo = hook.Repository().FullName
+
+	// Handlers are named after the GitHub handler with the same semantics.
+	var handle func() (*v1alpha1.InterceptorResponse, error)
+	switch h := hook.(type) {
+	case *scm.PushHook:
+		info.handler = "push"
+		total := scmTotalCommits(in.Body)
+		handle = func() (*v1alpha1.InterceptorResponse, error) { return scmPush(ctx, client, cfg, h, total) }
+	case *scm.PullRequestHook:
+		info.handler = "pull_request"
+		handle = func() (*v1alpha1.InterceptorResponse, error) { return scmPullRequest(ctx, client, cfg, h) }
+	case *scm.PullRequestCommentHook:
+		info.handler = "issue_comment"
+		handle = func() (*v1alpha1.InterceptorResponse, error) { return scmPullRequestComment(ctx, client, cfg, h) }
+	default:
+		return nil, Errorf(codes.Unimplemented, "unsupported event type: %s", hook.Kind())
+	}
+	return s.deliverOnce(in, handle)
+}
+
+// parseWebhook parses a webhook with go-scm, which also checks its signature
+// or token against the webhook secrets. Unlike GitHub payloads, the payloads
+// of other SCMs must be signed if secrets are configured.
+func (s *Server) parseWebhook(client *scm.Client, in *v1alpha1.InterceptorRequest) (scm.Webhook, error) {
+	var secrets [][]byte
+	if s.secrets != nil {
+		var err error
+		if secrets, err = s.secrets.Secrets(); err != nil {
+			return nil, Errorf(codes.Unavailable, "error reading webhook secrets: %v", err)
+		}
+	}
+	if len(secrets) == 0 {
+		if s.requireSignature {
+			return nil, Error(codes.Unauthenticated, "no webhook secret configured")
+		}
+		// An empty secret skips validation.
+		secrets = [][]byte{nil}
+	}
+
+	var err error
+	for _, secret := range secrets {
+		var hook scm.Webhook
+		hook, err = client.Webhooks.Parse(webhookRequest(in), func(scm.Webhook) (string, error) {
+			return string(secret), nil
+		})
+		if err == nil {
+			return hook, nil
+		}
+		if !errors.Is(err, scm.ErrSignatureInvalid) {
+			break
+		}
+	}
+	switch {
+	case errors.Is(err, scm.ErrSignatureInvalid):
+		return nil, Error(codes.InvalidArgument, "unknown signature")
+	case scm.IsUnknownWebhook(err):
+		return nil, Errorf(codes.Unimplemented, "unsupported event type: %v", err)
+	}
+	return nil, Errorf(codes.InvalidArgument, "error parsing event: %v", err)
+}
+
+// webhookRequest rebuilds the request an event was delivered with. Bitbucket
+// Cloud passes the secret as a query parameter of the event URL.
+func webhookRequest(in *v1alpha1.InterceptorRequest) *http.Request {
+	target := "/"
+	if in.Context != nil && in.Context.EventURL != "" {
+		target = in.Context.EventURL
+	}
+	r, err := http.NewRequest(http.MethodPost, target, strings.NewReader(in.Body))
+	if err != nil {
+		r, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(in.Body))
+	}
+	r.Header = http.Header(in.Header).Clone()
+	return r
+}
+
+// scmTotalCommits returns the number of commits pushed as reported by GitLab
+// push payloads, which list at most 20 of them, or 0 if it is not reported.
+// go-scm does not expose it.
+func scmTotalCommits(body string) int {
+	var payload struct {
+		TotalCommitsCount int `json:"total_commits_count"`
+	}
+	if err := json.Unmarshal([]byte(body), &payload); err != nil {
+		return 0
+	}
+	return payload.TotalCommitsCount
+}
+
+// scmPush handles a push. totalCommits is the number of commits pushed, if
+// the payload may not list all of them, or 0.
+func scmPush(ctx context.Context, client *scm.Client, cfg *pb.Config, hook *scm.PushHook, totalCommits int) (*v1alpha1.InterceptorResponse, error) {
+	if cfg.GetPush() == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for push")
+	}
+
+	if hook.After == zeroSHA {
+		return nil, Error(codes.FailedPrecondition, "ref was deleted - nothing to do")
+	}
+
+	patterns := cfg.GetPush().GetRef()
+	if patterns == nil {
+		// Default to all branches and tags.
+		patterns = []string{"refs/heads/*", "refs/tags/*"}
+	}
+	if !matchGlob(patterns, hook.Ref) {
+		return nil, Error(codes.FailedPrecondition, "did not find matching ref pattern")
+	}
+
+	ext := map[string]interface{}{
+		"git": bindings.Git{
+			URL:      hook.Repo.Clone,
+			Revision: hook.After,
+			Ref:      hook.Ref,
+		},
+		"github": bindings.GitHub{
+			Owner: hook.Repo.Namespace,
+			Repo:  hook.Repo.Name,
+		},
+	}
+
+	// New refs have nothing to compare against, so they always run.
+	if hook.Before != zeroSHA && !hook.Created {
+		files, resp, err := filterPaths(cfg.GetPush().GetPaths(), func() ([]string, error) {
+			return scmPushFiles(ctx, client, hook, totalCommits)
+		})
+		if resp != nil || err != nil {
+			return resp, err
+		}
+		if files != nil {
+			ext["changed_files"] = files
+		}
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue:   true,
+		Extensions: ext,
+	}, nil
+}
+
+func scmPullRequest(ctx context.Context, client *scm.Client, cfg *pb.Config, hook *scm.PullRequestHook) (*v1alpha1.InterceptorResponse, error) {
+	prCfg := cfg.GetPullRequest()
+	if prCfg == nil {
+		return nil, Error(codes.FailedPrecondition, "trigger not configured for pull_request")
+	}
+
+	action := scmAction(hook)
+	if !containsString(actionsOrDefault(prCfg), action) {
+		return nil, Errorf(codes.Unimplemented, "unsupported action %q", action)
+	}
+
+	pr := &hook.PullRequest
+	if prCfg.GetSkipDrafts() && pr.Draft {
+		return nil, Error(codes.FailedPrecondition, "skipping draft pull request")
+	}
+
+	if err := checkLabels(prCfg, scmLabelNames(pr.Labels)); err != nil {
+		return nil, err
+	}
+
+	patterns := prCfg.GetBranch()
+	if patterns == nil {
+		// Default to all branches
+		patterns = []string{"**"}
+	}
+	if !matchGlob(patterns, scmBaseRef(pr)) {
+		return &v1alpha1.InterceptorResponse{
+			Continue: false,
+			Status: v1alpha1.Status{
+				Code:    codes.FailedPrecondition,
+				Message: "did not find matching branch pattern",
+			},
+		}, nil
+	}
+
+	git, gh := scmPullRequestBindings(hook.Repo, pr)
+	ext := map[string]interface{}{
+		"git":    git,
+		"github": gh,
+	}
+
+	files, resp, err := filterPaths(prCfg.GetPaths(), func() ([]string, error) {
+		return scmAPI{client}.pullRequestFiles(ctx, hook.Repo.Namespace, hook.Repo.Name, pr.Number)
+	})
+	if resp != nil || err != nil {
+		return resp, err
+	}
+	if files != nil {
+		ext["changed_files"] = files
+	}
+
+	if commentCfg := prCfg.GetComment(); commentCfg != nil {
+		p := trustedPush{
+			org:    hook.Repo.Namespace,
+			repo:   hook.Repo.Name,
+			number: pr.Number,
+			author: pr.Author.Login,
+			labels: scmLabelNames(pr.Labels),
+		}
+		if action == "synchronize" {
+			p.pusher = hook.Sender.Login
+		}
+		if err := checkTrusted(ctx, scmAPI{client}, commentCfg, p); err != nil {
+			return nil, err
+		}
+	}
+
+	return &v1alpha1.InterceptorResponse{
+		Continue:   true,
+		Extensions: ext,
+	}, nil
+}
+
+func scmPullRequestComment(ctx context.Context, client *scm.Client, cfg *pb.Config, hook *scm.PullRequestCommentHook) (*v1alpha1.InterceptorResponse, error) {
+	if hook.Action != scm.ActionCreate {
+		return nil, Errorf(codes.Unimplemented, "unsupported action")
+	}
+
+	commentCfg := cfg.GetPullRequest().GetComment()
+	if commentCfg == nil {
+		return nil, Error(codes.FailedPrecondition, "comment config not enabled")
+	}
+
+	api := scmAPI{client}
+	org := hook.Repo.Namespace
+	repo := hook.Repo.Name
+	number := hook.PullRequest.Number
+	if err := checkRemoveLabel(ctx, api, commentCfg, org, repo, number, hook.Comment.Body, hook.Comment.Author.Login); err != nil {
+		return nil, err
+	}
+
+	cmds, cmd, err := matchComment(commentCfg, hook.Comment.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	src := commentApprovers(ctx, api, org, repo, number, commentCfg)
+	if err := checkApprover(ctx, api, org, repo, src, hook.Comment.Author.Login); err != nil {
+		return nil, err
+	}
+	addApprovalLabel(ctx, api, commentCfg, org, repo, number)
+
+	git, gh := scmPullRequestBindings(hook.Repo, &hook.PullRequest)
+	ext := commandExtensions(cmds, cmd)
+	ext["git"] = git
+	ext["github"] = gh
+	return &v1alpha1.InterceptorResponse{
+		Continue:   true,
+		Extensions: ext,
+	}, nil
+}
+
+// scmAction returns the GitHub name of a pull request action, so that
+// actions are configured the same way for all SCMs. GitLab reports new
+// commits as updates, with the previous head commit.
+func scmAction(hook *scm.PullRequestHook) string {
+	switch hook.Action {
+	case scm.ActionSync:
+		return "synchronize"
+	case scm.ActionUpdate:
+		if hook.Changes.Base.Sha.From != "" {
+			return "synchronize"
+		}
+		return "edited"
+	}
+	return hook.Action.String()
+}
+
+// scmBaseRef returns the branch a pull request merges into. GitLab reports
+// the default branch as the base ref, but the right branch as the target.
+func scmBaseRef(pr *scm.PullRequest) string {
+	if pr.Target != "" {
+		return pr.Target
+	}
+	return pr.Base.Ref
+}
+
+// scmPullRequestBindings returns the git and github extensions of a pull
+// request in repo. The clone depth, commit count and merge ref are not
+// known.
+func scmPullRequestBindings(repo scm.Repository, pr *scm.PullRequest) (bindings.Git, bindings.GitHub) {
+	url := pr.Head.Repo.Clone
+	if url == "" {
+		url = repo.Clone
+	}
+	sha := pr.Sha
+	if sha == "" {
+		sha = pr.Head.Sha
+	}
+	headRef := pr.Source
+	if headRef == "" {
+		headRef = pr.Head.Ref
+	}
+	git := bindings.Git{
+		URL:      url,
+		Revision: sha,
+		Ref:      pr.Ref,
+	}
+	gh := bindings.GitHub{
+		Owner: repo.Namespace,
+		Repo:  repo.Name,
+		PullRequest: &bindings.PullRequest{
+			Number:  pr.Number,
+			Author:  pr.Author.Login,
+			BaseURL: repo.Clone,
+			BaseRef: scmBaseRef(pr),
+			BaseSHA: pr.Base.Sha,
+			HeadRef: headRef,
+			HeadSHA: sha,
+			Labels:  scmLabelNames(pr.Labels),
+			Draft:   pr.Draft,
+			PullRef: pr.Ref,
+		},
+	}
+	return git, gh
+}
+
+// scmLabelNames returns the names of labels, never nil.
+func scmLabelNames(labels []*scm.Label) []string {
+	names := make([]string, 0, len(labels))
+	for _, l := range labels {
+		names = append(names, l.Name)
+	}
+	return names
+}
+
+// changeNames returns the names of changed files. Renamed files are listed
+// under both their old and new names.
+func changeNames(changes []*scm.Change) []string {
+	var names []string
+	for _, c := range changes {
+		names = append(names, c.Path)
+		if c.PreviousPath != "" && c.PreviousPath != c.Path {
+			names = append(names, c.PreviousPath)
+		}
+	}
+	return names
+}
+
+// scmPushFiles lists the files changed by a push. GitLab does not report
+// the commit before the push, so the files of the commits in the payload are
+// used instead. errTruncated is returned if totalCommits is more than the
+// payload lists.
+func scmPushFiles(ctx context.Context, client *scm.Client, hook *scm.PushHook, totalCommits int) ([]string, error) {
+	if hook.Before != "" {
+		var changes []*scm.Change
+		opts := &scm.ListOptions{Size: 100}
+		for {
+			page, resp, err := client.Git.CompareCommits(ctx, scm.Join(hook.Repo.Namespace, hook.Repo.Name), hook.Before, hook.After, opts)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, page...)
+			if resp == nil || resp.Page.Next == 0 {
+				break
+			}
+			opts.Page = resp.Page.Next
+		}
+		return changeNames(changes), nil
+	}
+	if totalCommits > len(hook.Commits) {
+		return nil, errTruncated
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, c := range hook.Commits {
+		for _, files := range [][]string{c.Added, c.Modified, c.Removed} {
+			for _, f := range files {
+				if !seen[f] {
+					seen[f] = true
+					names = append(names, f)
+				}
+			}
+		}
+	}
+	return names, nil
+}
+
+// scmAPI is the repoAPI of SCMs other than GitHub.
+type scmAPI struct {
+	client *scm.Client
+}
+
+func (a scmAPI) file(ctx context.Context, org, repo, path, revision string) (string, error) {
+	c, resp, err := a.client.Contents.Find(ctx, scm.Join(org, repo), path, revision)
+	if scm.IsScmNotFound(err) || (err != nil && resp != nil && resp.Status == http.StatusNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(c.Data), nil
+}
+
+func (a scmAPI) orgMember(ctx context.Context, org, user string) (bool, error) {
+	ok, _, err := a.client.Organizations.IsMember(ctx, org, user)
+	return ok, err
+}
+
+func (a scmAPI) teamMember(ctx context.Context, org, slug, user string) (bool, error) {
+	opts := &scm.ListOptions{Size: 100}
+	for {
+		teams, resp, err := a.client.Organizations.ListTeams(ctx, org, opts)
+		if err != nil {
+			return false, err
+		}
+		for _, t := range teams {
+			if t.Slug == slug || (t.Slug == "" && strings.EqualFold(t.Name, slug)) {
+				return a.listedInTeam(ctx, t.ID, user)
+			}
+		}
+		if resp == nil || resp.Page.Next == 0 {
+			return false, nil
+		}
+		opts.Page = resp.Page.Next
+	}
+}
+
+func (a scmAPI) listedInTeam(ctx context.Context, id int, user string) (bool, error) {
+	opts := &scm.ListOptions{Size: 100}
+	for {
+		members, resp, err := a.client.Organizations.ListTeamMembers(ctx, id, "all", opts)
+		if err != nil {
+			return false, err
+		}
+		for _, m := range members {
+			if strings.EqualFold(m.Login, user) {
+				return true, nil
+			}
+		}
+		if resp == nil || resp.Page.Next == 0 {
+			return false, nil
+		}
+		opts.Page = resp.Page.Next
+	}
+}
+
+func (a scmAPI) pullRequestFiles(ctx context.Context, org, repo string, number int) ([]string, error) {
+	var changes []*scm.Change
+	opts := &scm.ListOptions{Size: 100}
+	for {
+		page, resp, err := a.client.PullRequests.ListChanges(ctx, scm.Join(org, repo), number, opts)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, page...)
+		if resp == nil || resp.Page.Next == 0 {
+			break
+		}
+		opts.Page = resp.Page.Next
+	}
+	return changeNames(changes), nil
+}
+
+func (a scmAPI) addLabel(ctx context.Context, org, repo string, number int, label string) error {
+	_, err := a.client.PullRequests.AddLabel(ctx, scm.Join(org, repo), number, label)
+	return err
+}
+
+func (a scmAPI) removeLabel(ctx context.Context, org, repo string, number int, label string) error {
+	resp, err := a.client.PullRequests.DeleteLabel(ctx, scm.Join(org, repo), number, label)
+	if scm.IsScmNotFound(err) || (err != nil && resp != nil && resp.Status == http.StatusNotFound) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,400 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/jenkins-x/go-scm/scm"
+	"github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/github/bindings"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestDetectDriver(t *testing.T) {
+	for _, tc := range []struct {
+		header http.Header
+		want   string
+	}{
+		{header: http.Header{"X-Github-Event": {"push"}}, want: "github"},
+		{header: http.Header{"X-Github-Event": {"push"}, "X-Gitea-Event": {"push"}}, want: "gitea"},
+		{header: http.Header{"X-Gitlab-Event": {"Push Hook"}}, want: "gitlab"},
+		{header: http.Header{"X-Event-Key": {"repo:push"}, "X-Hook-Uuid": {"a1b2"}}, want: "bitbucket"},
+		{header: http.Header{"X-Event-Key": {"repo:refs_changed"}}, want: "stash"},
+		{header: http.Header{}, want: ""},
+	} {
+		if got := detectDriver(tc.header); got != tc.want {
+			t.Errorf("detectDriver(%v): want %q, got %q", tc.header, tc.want, got)
+		}
+	}
+}
+
+// fakeGitea serves the Gitea API calls made for the testdata events.
+func fakeGitea(t *testing.T) *httptest.Server {
+	t.Helper()
+	pull, err := os.ReadFile("testdata/gitea_pull.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/version", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(`{"version": "1.22.0"}`))
+	})
+	mux.HandleFunc("/api/v1/repos/gogits/hello-world/pulls/2", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write(pull)
+	})
+	mux.HandleFunc("/api/v1/repos/gogits/hello-world/contents/OWNERS", func(rw http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(rw).Encode(map[string]string{
+			"type":     "file",
+			"encoding": "base64",
+			"content":  base64.StdEncoding.EncodeToString([]byte("approvers:\n- unknwon\n")),
+		})
+	})
+	mux.HandleFunc("/api/v1/repos/jcitizen/my-repo/pulls/1.patch", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte(strings.Join([]string{
+			"diff --git a/LICENSE b/LICENSE",
+			"new file mode 100644",
+			"--- /dev/null",
+			"+++ b/LICENSE",
+			"@@ -0,0 +1 @@",
+			"+BSD",
+			"",
+		}, "\n")))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestServer_SCM(t *testing.T) {
+	gitea := fakeGitea(t)
+	giteaPR := &bindings.PullRequest{
+		Number:  1,
+		Author:  "jcitizen",
+		BaseURL: "https://try.gitea.io/jcitizen/my-repo.git",
+		BaseRef: "master",
+		BaseSHA: "39af58f1eff02aa308e16913e887c8d50362b474",
+		HeadRef: "feature",
+		HeadSHA: "2eba238e33607c1fa49253182e9fff42baafa1eb",
+		Labels:  []string{},
+		PullRef: "refs/pull/1/head",
+	}
+
+	for _, tc := range []struct {
+		name    string
+		file    string
+		header  map[string][]string
+		cfg     *pb.Config
+		secrets SecretSource
+		// sign signs the payload with the given secret.
+		sign string
+		want codes.Code
+		// ext are the expected extensions, if the event continues.
+		ext map[string]interface{}
+	}{
+		{
+			name:   "gitea push",
+			file:   "testdata/gitea_push.json",
+			header: map[string][]string{"X-Gitea-Event": {"push"}, "X-Github-Event": {"push"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{Ref: []string{"refs/heads/master"}}},
+			ext: map[string]interface{}{
+				"git": bindings.Git{
+					URL:      "http://try.gitea.io/gogits/hello-world.git",
+					Revision: "4522cbcefc20728a5b72b3a86af35e608622c514",
+					Ref:      "refs/heads/master",
+				},
+				"github": bindings.GitHub{Owner: "gogits", Repo: "hello-world"},
+			},
+		},
+		{
+			name:   "gitea push to other branch",
+			file:   "testdata/gitea_push.json",
+			header: map[string][]string{"X-Gitea-Event": {"push"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{Ref: []string{"refs/heads/release-*"}}},
+			want:   codes.FailedPrecondition,
+		},
+		{
+			name:    "gitea push signed",
+			file:    "testdata/gitea_push.json",
+			header:  map[string][]string{"X-Gitea-Event": {"push"}},
+			cfg:     &pb.Config{Push: &pb.PushConfig{}},
+			secrets: staticSecrets{[]byte("hunter2"), []byte("hunter3")},
+			sign:    "hunter3",
+			ext: map[string]interface{}{
+				"git": bindings.Git{
+					URL:      "http://try.gitea.io/gogits/hello-world.git",
+					Revision: "4522cbcefc20728a5b72b3a86af35e608622c514",
+					Ref:      "refs/heads/master",
+				},
+				"github": bindings.GitHub{Owner: "gogits", Repo: "hello-world"},
+			},
+		},
+		{
+			name:    "gitea push unknown signature",
+			file:    "testdata/gitea_push.json",
+			header:  map[string][]string{"X-Gitea-Event": {"push"}},
+			cfg:     &pb.Config{Push: &pb.PushConfig{}},
+			secrets: staticSecrets{[]byte("hunter2")},
+			sign:    "hunter1",
+			want:    codes.InvalidArgument,
+		},
+		{
+			name:    "gitea push unsigned",
+			file:    "testdata/gitea_push.json",
+			header:  map[string][]string{"X-Gitea-Event": {"push"}},
+			cfg:     &pb.Config{Push: &pb.PushConfig{}},
+			secrets: staticSecrets{[]byte("hunter2")},
+			want:    codes.InvalidArgument,
+		},
+		{
+			name:   "gitlab push",
+			file:   "testdata/gitlab_push.json",
+			header: map[string][]string{"X-Gitlab-Event": {"Push Hook"}},
+			cfg: &pb.Config{Push: &pb.PushConfig{
+				Paths: &pb.Paths{Include: []string{"**/*.md"}},
+			}},
+			ext: map[string]interface{}{
+				"git": bindings.Git{
+					URL:      "https://gitlab.com/gitlab-org/hello-world.git",
+					Revision: "2adc9465c4edfc33834e173fe89436a7cb899a1d",
+					Ref:      "refs/heads/master",
+				},
+				"github":        bindings.GitHub{Owner: "gitlab-org", Repo: "hello-world"},
+				"changed_files": []string{"README.md"},
+			},
+		},
+		{
+			name:   "gitlab push excluded paths",
+			file:   "testdata/gitlab_push.json",
+			header: map[string][]string{"X-Gitlab-Event": {"Push Hook"}},
+			cfg: &pb.Config{Push: &pb.PushConfig{
+				Paths: &pb.Paths{Exclude: []string{"*.md"}},
+			}},
+			want: codes.FailedPrecondition,
+		},
+		{
+			name:   "gitea pull request",
+			file:   "testdata/gitea_pull_request.json",
+			header: map[string][]string{"X-Gitea-Event": {"pull_request"}},
+			cfg: &pb.Config{PullRequest: &pb.PullRequestConfig{
+				Paths: &pb.Paths{Include: []string{"LICENSE"}},
+			}},
+			ext: map[string]interface{}{
+				"git": bindings.Git{
+					URL:      "https://try.gitea.io/jcitizen/my-repo.git",
+					Revision: "2eba238e33607c1fa49253182e9fff42baafa1eb",
+					Ref:      "refs/pull/1/head",
+				},
+				"github":        bindings.GitHub{Owner: "jcitizen", Repo: "my-repo", PullRequest: giteaPR},
+				"changed_files": []string{"LICENSE"},
+			},
+		},
+		{
+			name:   "gitea pull request required label",
+			file:   "testdata/gitea_pull_request.json",
+			header: map[string][]string{"X-Gitea-Event": {"pull_request"}},
+			cfg: &pb.Config{PullRequest: &pb.PullRequestConfig{
+				RequiredLabels: []string{"ok-to-test"},
+			}},
+			want: codes.FailedPrecondition,
+		},
+		{
+			name:   "gitea pull request action",
+			file:   "testdata/gitea_pull_request.json",
+			header: map[string][]string{"X-Gitea-Event": {"pull_request"}},
+			cfg: &pb.Config{PullRequest: &pb.PullRequestConfig{
+				Actions: []string{"closed"},
+			}},
+			want: codes.Unimplemented,
+		},
+		{
+			name:   "gitea comment",
+			file:   "testdata/gitea_issue_comment.json",
+			header: map[string][]string{"X-Gitea-Event": {"issue_comment"}},
+			cfg: &pb.Config{PullRequest: &pb.PullRequestConfig{
+				Comment: &pb.PullRequestConfig_CommentConfig{},
+			}},
+			ext: map[string]interface{}{
+				"git": bindings.Git{
+					URL:      "https://try.gitea.io/gogits/hello-world.git",
+					Revision: "4f5e7d8f15cf79387cfd8a0d30c58855ab61e138",
+					Ref:      "refs/pull/2/head",
+				},
+				"github": bindings.GitHub{Owner: "gogits", Repo: "hello-world", PullRequest: &bindings.PullRequest{
+					Number:  2,
+					Author:  "unknwon",
+					BaseURL: "http://try.gitea.io/gogits/hello-world.git",
+					BaseRef: "master",
+					BaseSHA: "39af58f1eff02aa308e16913e887c8d50362b474",
+					HeadRef: "feature",
+					HeadSHA: "4f5e7d8f15cf79387cfd8a0d30c58855ab61e138",
+					Labels:  []string{},
+					PullRef: "refs/pull/2/head",
+				}},
+				"commands": []bindings.Command{{Name: "ok-to-test", Args: []string{}, Flags: map[string]string{}}},
+				"command":  bindings.Command{Name: "ok-to-test", Args: []string{}, Flags: map[string]string{}},
+			},
+		},
+		{
+			name:   "gitea comment not matching",
+			file:   "testdata/gitea_issue_comment.json",
+			header: map[string][]string{"X-Gitea-Event": {"issue_comment"}},
+			cfg: &pb.Config{PullRequest: &pb.PullRequestConfig{
+				Comment: &pb.PullRequestConfig_CommentConfig{Commands: []string{"retest"}},
+			}},
+			want: codes.FailedPrecondition,
+		},
+		{
+			name:   "unsupported event",
+			file:   "testdata/gitea_push.json",
+			header: map[string][]string{"X-Gitea-Event": {"fork"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{}},
+			want:   codes.Unimplemented,
+		},
+		{
+			name:   "unconfigured SCM",
+			file:   "testdata/gitea_push.json",
+			header: map[string][]string{"X-Event-Key": {"repo:refs_changed"}},
+			cfg:    &pb.Config{Push: &pb.PushConfig{}},
+			want:   codes.Unimplemented,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := os.ReadFile(tc.file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.sign != "" {
+				tc.header["X-Gitea-Signature"] = []string{strings.TrimPrefix(signature(body, []byte(tc.sign)), "sha256=")}
+			}
+			opts := []Option{
+				WithSCMServer("gitea", gitea.URL, ""),
+				WithSCMServer("gitlab", "https://gitlab.example.com", ""),
+			}
+			if tc.secrets != nil {
+				opts = append(opts, WithWebhookSecrets(tc.secrets))
+			}
+			s := New(http.DefaultClient, nil, opts...)
+
+			b := new(bytes.Buffer)
+			if err := json.NewEncoder(b).Encode(&v1alpha1.InterceptorRequest{
+				Body:              string(body),
+				Header:            tc.header,
+				InterceptorParams: map[string]interface{}{"config": tc.cfg},
+			}); err != nil {
+				t.Fatal(err)
+			}
+			rw := httptest.NewRecorder()
+			s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", b))
+			var resp v1alpha1.InterceptorResponse
+			if err := json.NewDecoder(rw.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Status.Code != tc.want {
+				t.Fatalf("want code %v, got %+v", tc.want, resp.Status)
+			}
+			if tc.want != codes.OK {
+				return
+			}
+			if !resp.Continue {
+				t.Fatal("expected event to continue")
+			}
+			if diff := cmp.Diff(jsonValue(t, tc.ext), jsonValue(t, resp.Extensions)); diff != "" {
+				t.Errorf("-want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSCMPushFiles(t *testing.T) {
+	hook := &scm.PushHook{Commits: []scm.PushCommit{
+		{Added: []string{"README.md"}, Modified: []string{"main.go"}},
+		{Modified: []string{"main.go"}, Removed: []string{"old.go"}},
+	}}
+	for _, tc := range []struct {
+		name  string
+		total int
+		want  []string
+		err   error
+	}{
+		{name: "total not reported", want: []string{"README.md", "main.go", "old.go"}},
+		{name: "all commits listed", total: 2, want: []string{"README.md", "main.go", "old.go"}},
+		{name: "commits not listed", total: 21, err: errTruncated},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := scmPushFiles(context.Background(), nil, hook, tc.total)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("want error %v, got %v", tc.err, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("-want +got: %s", diff)
+			}
+		})
+	}
+}
+
+func TestSCMTotalCommits(t *testing.T) {
+	for body, want := range map[string]int{
+		`{"object_kind": "push", "total_commits_count": 32}`: 32,
+		`{"object_kind": "push"}`:                            0,
+		`not json`:                                           0,
+	} {
+		if got := scmTotalCommits(body); got != want {
+			t.Errorf("scmTotalCommits(%s): want %d, got %d", body, want, got)
+		}
+	}
+	b, err := os.ReadFile("testdata/gitlab_push.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := scmTotalCommits(string(b)); got != 1 {
+		t.Errorf("want 1 commit in testdata/gitlab_push.json, got %d", got)
+	}
+}
+
+func TestSCMAction(t *testing.T) {
+	for _, tc := range []struct {
+		hook *scm.PullRequestHook
+		want string
+	}{
+		{hook: &scm.PullRequestHook{Action: scm.ActionOpen}, want: "opened"},
+		{hook: &scm.PullRequestHook{Action: scm.ActionSync}, want: "synchronize"},
+		{hook: &scm.PullRequestHook{Action: scm.ActionUpdate}, want: "edited"},
+		{
+			hook: func() *scm.PullRequestHook {
+				h := &scm.PullRequestHook{Action: scm.ActionUpdate}
+				h.Changes.Base.Sha.From = "2eba238e33607c1fa49253182e9fff42baafa1eb"
+				return h
+			}(),
+			want: "synchronize",
+		},
+	} {
+		if got := scmAction(tc.hook); got != tc.want {
+			t.Errorf("%v: want %q, got %q", tc.hook.Action, tc.want, got)
+		}
+	}
+}
+
+// jsonValue returns v as decoded from JSON, to compare extensions.
+func jsonValue(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out interface{}
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
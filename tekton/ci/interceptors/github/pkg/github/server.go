@@ -6,87 +6,143 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v34/github"
 	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
 	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 )
 
 type Server struct {
 	client *http.Client
+	logger *zap.SugaredLogger
 
 	// Maps event types -> Interceptor handlers.
 	router map[string]Interceptor
 
-	// Webhook Secret
-	webhookSecret []byte
+	// Secrets webhook payloads can be signed with.
+	secrets SecretSource
+	// If set, unsigned payloads are rejected.
+	requireSignature bool
+	// Optional cache of handled deliveries, to reject replays.
+	deliveries *deliveryCache
+
+	// Optional response cache and rate limit handling for GitHub API calls.
+	cache     *cacheTransport
+	rateLimit *rateLimitTransport
+
+	// SCMs other than GitHub, by go-scm driver name.
+	scms map[string]*scmServer
+
+	// GitHub API endpoint, defaults to https://api.github.com/.
+	baseURL *url.URL
+	// Optional GitHub App and personal access token credentials.
+	app   *AppTokenSource
+	token string
 }
 
-func New(c *http.Client, webhookSecret []byte) *Server {
-	return &Server{
-		client:        c,
-		webhookSecret: webhookSecret,
+func New(c *http.Client, webhookSecret []byte, opts ...Option) *Server {
+	s := &Server{
+		client: c,
+		logger: zap.NewNop().Sugar(),
 		router: map[string]Interceptor{
-			"issue_comment": &IssueComment{},
-			"push":          &Push{},
-			"pull_request":  &PullRequest{},
+			"issue_comment":       &IssueComment{},
+			"push":                &Push{},
+			"pull_request":        &PullRequest{},
+			"pull_request_review": &PullRequestReview{},
+			"check_run":           &CheckRun{},
+			"check_suite":         &CheckSuite{},
+			"release":             &Release{},
 		},
 	}
+	if len(webhookSecret) > 0 {
+		s.secrets = staticSecrets{webhookSecret}
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if s.app != nil {
+		s.app.baseURL = s.baseURL
+	}
+	return s
 }
 
 func (s *Server) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
-	resp, err := s.handle(r)
-	if err != nil {
-		// non-OK http should generally be reserved for network or other
-		// system issues. For any handler errors, wrap in an
-		// InterceptorResponse.
-		if resp == nil {
-			resp = &v1alpha1.InterceptorResponse{
-				Continue: false,
-			}
-		}
-		if serr := new(StatusError); errors.As(err, serr) {
-			resp.Status = serr.Status
-		} else {
-			resp.Status = v1alpha1.Status{
-				Code:    codes.Internal,
-				Message: err.Error(),
-			}
-		}
+	start := time.Now()
+	info := new(requestInfo)
+	var resp *v1alpha1.InterceptorResponse
+	in := new(v1alpha1.InterceptorRequest)
+	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
+		resp = response(nil, Errorf(codes.InvalidArgument, "error parsing request: %v", err))
+	} else {
+		resp = response(s.handle(r.Context(), in, info))
 	}
+	s.record(info, resp, time.Since(start))
 
 	if err := json.NewEncoder(rw).Encode(resp); err != nil {
-		fmt.Println(err)
+		s.logger.Errorw("error writing response", "delivery", info.delivery, "error", err)
 		rw.WriteHeader(http.StatusInternalServerError)
 		fmt.Fprint(rw, "error writing response:", err) //nolint:errcheck // best-effort error response
 		return
 	}
 }
 
-func (s *Server) handle(r *http.Request) (*v1alpha1.InterceptorResponse, error) {
-	in := new(v1alpha1.InterceptorRequest)
+// response wraps handler errors in an InterceptorResponse. Non-OK http should
+// generally be reserved for network or other system issues.
+func response(resp *v1alpha1.InterceptorResponse, err error) *v1alpha1.InterceptorResponse {
+	if err == nil {
+		return resp
+	}
+	if resp == nil {
+		resp = &v1alpha1.InterceptorResponse{
+			Continue: false,
+		}
+	}
+	if serr := new(StatusError); errors.As(err, serr) {
+		resp.Status = serr.Status
+	} else {
+		resp.Status = v1alpha1.Status{
+			Code:    codes.Internal,
+			Message: err.Error(),
+		}
+	}
+	return resp
+}
 
-	if err := json.NewDecoder(r.Body).Decode(in); err != nil {
-		return nil, Errorf(codes.InvalidArgument, "error parsing request: %v", err)
+func (s *Server) handle(ctx context.Context, in *v1alpha1.InterceptorRequest, info *requestInfo) (*v1alpha1.InterceptorResponse, error) {
+	info.delivery = deliveryID(http.Header(in.Header))
+	ctx = withLogger(ctx, s.logger.With("delivery", info.delivery))
+
+	// Events from other SCMs are parsed, and their signature validated, with
+	// go-scm.
+	if driver := detectDriver(http.Header(in.Header)); driver != "" && driver != "github" {
+		info.scm = driver
+		return s.handleSCM(ctx, driver, in, info)
 	}
+	info.scm = "github"
+	eventType := in.Header["X-Github-Event"]
+	info.event = strings.Join(eventType, ",")
 
 	// Validate webhook signature.
-	headers := http.Header(in.Header)
-	if sig := headers.Get("X-Hub-Signature-256"); sig != "" {
-		fmt.Println(sig)
-		if err := github.ValidateSignature(sig, []byte(in.Body), s.webhookSecret); err != nil {
-			return nil, Errorf(codes.InvalidArgument, "unknown signature: %v", err)
-		}
+	if err := s.validateSignature(http.Header(in.Header), []byte(in.Body)); err != nil {
+		return nil, err
+	}
+	// GitHub sends the delivery ID with every signed delivery.
+	if s.deliveries != nil && info.delivery == "" && http.Header(in.Header).Get("X-Hub-Signature-256") != "" {
+		return nil, Error(codes.InvalidArgument, "missing X-GitHub-Delivery header")
 	}
 
 	// Route request.
-	eventType := in.Header["X-Github-Event"]
 	var i Interceptor
 	for _, e := range eventType {
 		var ok bool
 		i, ok = s.router[e]
 		if ok {
+			info.handler = e
 			break
 		}
 	}
@@ -94,13 +150,68 @@ func (s *Server) handle(r *http.Request) (*v1alpha1.InterceptorResponse, error)
 		return nil, Errorf(codes.Unimplemented, "unsupported event type: %s", eventType)
 	}
 
+	event := new(commonEvent)
+	if err := json.Unmarshal([]byte(in.Body), event); err != nil {
+		return nil, Errorf(codes.InvalidArgument, "error parsing event: %v", err)
+	}
+	info.repo = event.GetRepo().GetFullName()
+
 	cfg, err := Unmarshal(in.InterceptorParams)
 	if err != nil {
 		return nil, Errorf(codes.InvalidArgument, "error reading config: %v", err)
 	}
+	if err := Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	// Events sent by a GitHub App carry the installation to authenticate
+	// as.
+	client, err := s.githubClient(ctx, event.GetInstallation().GetID())
+	if err != nil {
+		return nil, Errorf(codes.Unavailable, "error authenticating with GitHub: %v", err)
+	}
+
+	return s.deliverOnce(in, func() (*v1alpha1.InterceptorResponse, error) {
+		return i.Execute(ctx, client, cfg, in)
+	})
+}
+
+// deliverOnce handles a delivery with fn, and rejects replayed deliveries.
+// Deliveries that fail are forgotten so that they can be redelivered.
+func (s *Server) deliverOnce(in *v1alpha1.InterceptorRequest, fn func() (*v1alpha1.InterceptorResponse, error)) (*v1alpha1.InterceptorResponse, error) {
+	if s.deliveries == nil {
+		return fn()
+	}
+	key := deliveryKey(in)
+	if !s.deliveries.add(key) {
+		return nil, Errorf(codes.AlreadyExists, "delivery %s was already handled", deliveryID(http.Header(in.Header)))
+	}
+	resp, err := fn()
+	if err != nil {
+		s.deliveries.remove(key)
+	}
+	return resp, err
+}
 
-	// TODO: Add authenticated App/PAT client support.
-	return i.Execute(r.Context(), github.NewClient(s.client), cfg, in)
+// commonEvent holds the fields common to most events: the repository, and
+// the installation of events sent by GitHub Apps.
+type commonEvent struct {
+	Repo         *github.Repository   `json:"repository,omitempty"`
+	Installation *github.Installation `json:"installation,omitempty"`
+}
+
+func (e *commonEvent) GetRepo() *github.Repository {
+	if e == nil {
+		return nil
+	}
+	return e.Repo
+}
+
+func (e *commonEvent) GetInstallation() *github.Installation {
+	if e == nil {
+		return nil
+	}
+	return e.Installation
 }
 
 type Interceptor interface {
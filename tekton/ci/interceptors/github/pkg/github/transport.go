@@ -0,0 +1,301 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithCache caches GitHub API responses in memory, up to size bytes of
+// response bodies. Cached responses are revalidated with their ETag, and
+// GitHub does not count revalidated requests against the rate limit.
+func WithCache(size int64) Option {
+	return func(s *Server) {
+		if size > 0 {
+			s.cache = newCacheTransport(size)
+		}
+	}
+}
+
+// WithRateLimitWait delays GitHub API requests made after the rate limit
+// was exhausted until it resets, if that is at most maxWait away. Requests
+// fail immediately otherwise.
+func WithRateLimitWait(maxWait time.Duration) Option {
+	return func(s *Server) {
+		s.rateLimit = newRateLimitTransport(maxWait)
+	}
+}
+
+// transport returns the RoundTripper GitHub API requests are sent through,
+// before credentials are added.
+func (s *Server) transport() http.RoundTripper {
+	var t http.RoundTripper = s.client.Transport
+	if s.rateLimit != nil {
+		t = s.rateLimit.wrap(t)
+	}
+	if s.cache != nil {
+		t = s.cache.wrap(t)
+	}
+	return t
+}
+
+// credentialKey identifies the credentials of a request, without retaining
+// them. GitHub caches and rate limits responses per credentials.
+func credentialKey(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(auth))
+	return hex.EncodeToString(sum[:8])
+}
+
+// cacheEntry is a cached response.
+type cacheEntry struct {
+	key    string
+	etag   string
+	status int
+	header http.Header
+	body   []byte
+}
+
+// cacheTransport caches the responses of GET requests with an ETag, and
+// revalidates them with If-None-Match. The least recently used responses
+// are evicted once the bodies exceed size bytes.
+type cacheTransport struct {
+	size int64
+
+	mu      sync.Mutex
+	used    int64
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newCacheTransport(size int64) *cacheTransport {
+	return &cacheTransport{
+		size:    size,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+func (c *cacheTransport) wrap(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return c.roundTrip(base, r)
+	})
+}
+
+func (c *cacheTransport) roundTrip(base http.RoundTripper, r *http.Request) (*http.Response, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if r.Method != http.MethodGet || r.Header.Get("If-None-Match") != "" || r.Header.Get("Range") != "" {
+		return base.RoundTrip(r)
+	}
+
+	key := credentialKey(r) + " " + r.Header.Get("Accept") + " " + r.URL.String()
+	e := c.get(key)
+	if e != nil {
+		// RoundTrippers must not modify the original request.
+		r = r.Clone(r.Context())
+		r.Header.Set("If-None-Match", e.etag)
+	}
+	resp, err := base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if e != nil && resp.StatusCode == http.StatusNotModified {
+		cacheRequests.WithLabelValues(CacheHit).Inc()
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		header := e.header.Clone()
+		// Keep the rate limit of the revalidation, which go-github reads.
+		for k, v := range resp.Header {
+			if strings.HasPrefix(k, "X-Ratelimit-") {
+				header[k] = v
+			}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", e.status, http.StatusText(e.status)),
+			StatusCode:    e.status,
+			Proto:         resp.Proto,
+			ProtoMajor:    resp.ProtoMajor,
+			ProtoMinor:    resp.ProtoMinor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(e.body)),
+			ContentLength: int64(len(e.body)),
+			Request:       r,
+		}, nil
+	}
+
+	cacheRequests.WithLabelValues(CacheMiss).Inc()
+	etag := resp.Header.Get("ETag")
+	if resp.StatusCode != http.StatusOK || etag == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	c.put(&cacheEntry{
+		key:    key,
+		etag:   etag,
+		status: resp.StatusCode,
+		header: resp.Header.Clone(),
+		body:   body,
+	})
+	return resp, nil
+}
+
+func (c *cacheTransport) get(key string) *cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*cacheEntry)
+}
+
+func (c *cacheTransport) put(e *cacheEntry) {
+	size := int64(len(e.body))
+	if size > c.size {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[e.key]; ok {
+		c.remove(el)
+	}
+	c.entries[e.key] = c.order.PushFront(e)
+	c.used += size
+	for c.used > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// remove evicts an entry. c.mu must be held.
+func (c *cacheTransport) remove(el *list.Element) {
+	e := c.order.Remove(el).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.used -= int64(len(e.body))
+}
+
+// rateLimitTransport records the rate limit reported by GitHub, and delays
+// requests made with credentials whose rate limit is exhausted until it
+// resets.
+type rateLimitTransport struct {
+	maxWait time.Duration
+	now     func() time.Time
+
+	mu sync.Mutex
+	// resets holds when the rate limit of exhausted credentials resets.
+	resets map[string]time.Time
+}
+
+func newRateLimitTransport(maxWait time.Duration) *rateLimitTransport {
+	return &rateLimitTransport{
+		maxWait: maxWait,
+		now:     time.Now,
+		resets:  make(map[string]time.Time),
+	}
+}
+
+func (t *rateLimitTransport) wrap(base http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		return t.roundTrip(base, r)
+	})
+}
+
+func (t *rateLimitTransport) roundTrip(base http.RoundTripper, r *http.Request) (*http.Response, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	key := credentialKey(r)
+
+	if wait := t.wait(key); wait > 0 {
+		if wait > t.maxWait {
+			rateLimitRejections.Inc()
+			return nil, fmt.Errorf("GitHub API rate limit exceeded, resets in %v", wait.Round(time.Second))
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-r.Context().Done():
+			timer.Stop()
+			return nil, r.Context().Err()
+		case <-timer.C:
+		}
+		rateLimitWait.Add(wait.Seconds())
+	}
+
+	resp, err := base.RoundTrip(r)
+	if err != nil {
+		return nil, err
+	}
+	t.record(key, resp)
+	return resp, nil
+}
+
+// wait returns how long requests with the given credentials must wait.
+func (t *rateLimitTransport) wait(key string) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	reset, ok := t.resets[key]
+	if !ok {
+		return 0
+	}
+	wait := reset.Sub(t.now())
+	if wait <= 0 {
+		delete(t.resets, key)
+	}
+	return wait
+}
+
+// record reads the rate limit headers of a response. Requests are held when
+// the primary rate limit is exhausted, or GitHub asks to retry later because
+// of a secondary rate limit.
+func (t *rateLimitTransport) record(key string, resp *http.Response) {
+	var reset time.Time
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		resource := resp.Header.Get("X-RateLimit-Resource")
+		if resource == "" {
+			resource = "core"
+		}
+		rateLimitRemaining.WithLabelValues(resource).Set(float64(remaining))
+		if epoch, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil && remaining == 0 {
+			reset = time.Unix(epoch, 0)
+		}
+	}
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			reset = t.now().Add(time.Duration(seconds) * time.Second)
+		}
+	}
+	if reset.After(t.now()) {
+		t.mu.Lock()
+		t.resets[key] = reset
+		t.mu.Unlock()
+	}
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
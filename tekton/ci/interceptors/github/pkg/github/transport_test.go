@@ -0,0 +1,143 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCacheTransport(t *testing.T) {
+	ctx := context.Background()
+	var requests, revalidated int
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/tektoncd/plumbing/pulls/1", func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		etag := `"` + r.Header.Get("Authorization") + `"`
+		rw.Header().Set("X-RateLimit-Remaining", strconv.Itoa(5000-requests))
+		if r.Header.Get("If-None-Match") == etag {
+			revalidated++
+			rw.WriteHeader(http.StatusNotModified)
+			return
+		}
+		rw.Header().Set("ETag", etag)
+		_, _ = rw.Write([]byte(`{"number": 1, "title": "Cache responses"}`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := New(srv.Client(), nil, WithCache(1<<20), WithBaseURL(mustParseURL(srv.URL+"/")))
+	hits := testutil.ToFloat64(cacheRequests.WithLabelValues(CacheHit))
+	for i, token := range []string{"a", "a", "b"} {
+		s.token = token
+		client, err := s.githubClient(ctx, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pr, resp, err := client.PullRequests.Get(ctx, "tektoncd", "plumbing", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if pr.GetTitle() != "Cache responses" {
+			t.Errorf("request %d: unexpected pull request %+v", i, pr)
+		}
+		if want := 5000 - requests; resp.Rate.Remaining != want {
+			t.Errorf("request %d: want %d requests remaining, got %d", i, want, resp.Rate.Remaining)
+		}
+	}
+	// Responses are cached per credentials.
+	if revalidated != 1 {
+		t.Errorf("want 1 revalidated request, got %d", revalidated)
+	}
+	if got := testutil.ToFloat64(cacheRequests.WithLabelValues(CacheHit)) - hits; got != 1 {
+		t.Errorf("want 1 cache hit, got %v", got)
+	}
+}
+
+func TestCacheTransport_Evict(t *testing.T) {
+	c := newCacheTransport(10)
+	c.put(&cacheEntry{key: "a", body: []byte("12345")})
+	c.put(&cacheEntry{key: "b", body: []byte("12345")})
+	c.get("a")
+	c.put(&cacheEntry{key: "c", body: []byte("123")})
+	c.put(&cacheEntry{key: "too large", body: []byte("12345678901")})
+
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "too large": false} {
+		if got := c.get(key) != nil; got != want {
+			t.Errorf("%s cached: want %t, got %t", key, want, got)
+		}
+	}
+	if c.used != 8 {
+		t.Errorf("want 8 bytes used, got %d", c.used)
+	}
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	var requests int
+	base := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requests++
+		header := http.Header{}
+		switch r.URL.Path {
+		case "/exhausted":
+			header.Set("X-RateLimit-Remaining", "0")
+			header.Set("X-RateLimit-Reset", strconv.FormatInt(now.Add(time.Hour).Unix(), 10))
+		case "/secondary":
+			header.Set("Retry-After", "1")
+			return &http.Response{StatusCode: http.StatusForbidden, Header: header, Body: http.NoBody}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody}, nil
+	})
+	rl := newRateLimitTransport(5 * time.Second)
+	rl.now = func() time.Time { return now }
+	transport := &tokenTransport{token: "a", base: rl.wrap(base)}
+	client := &http.Client{Transport: transport}
+
+	get := func(token, path string) error {
+		transport.token = token
+		resp, err := client.Get("https://api.github.com" + path)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	if err := get("a", "/exhausted"); err != nil {
+		t.Fatal(err)
+	}
+	// The rate limit resets in an hour, too late to wait for.
+	rejections := testutil.ToFloat64(rateLimitRejections)
+	if err := get("a", "/"); err == nil {
+		t.Error("expected request to fail until the rate limit resets")
+	}
+	if got := testutil.ToFloat64(rateLimitRejections) - rejections; got != 1 {
+		t.Errorf("want 1 rejection, got %v", got)
+	}
+	// Other credentials have their own rate limit.
+	if err := get("b", "/"); err != nil {
+		t.Error(err)
+	}
+	if got := testutil.ToFloat64(rateLimitRemaining.WithLabelValues("core")); got != 0 {
+		t.Errorf("want 0 requests remaining, got %v", got)
+	}
+
+	// Secondary rate limits hold requests for Retry-After.
+	if err := get("c", "/secondary"); err != nil {
+		t.Fatal(err)
+	}
+	if wait := rl.wait(credentialKey(&http.Request{Header: http.Header{"Authorization": {"token c"}}})); wait != time.Second {
+		t.Errorf("want to wait 1s, got %v", wait)
+	}
+	rl.now = func() time.Time { return now.Add(time.Hour) }
+	before := requests
+	if err := get("a", "/"); err != nil {
+		t.Errorf("expected request to succeed once the rate limit reset: %v", err)
+	}
+	if requests != before+1 {
+		t.Error("expected request to be sent")
+	}
+}
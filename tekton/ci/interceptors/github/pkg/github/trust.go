@@ -0,0 +1,126 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"google.golang.org/grpc/codes"
+)
+
+// defaultTrustedAssociations are the author associations trusted if none are
+// configured.
+var defaultTrustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// trustedPush describes a pull request event to check trust of.
+type trustedPush struct {
+	org, repo string
+	number    int
+	// author of the pull request, and their association with the repo.
+	// association is empty for SCMs that do not report it.
+	author, association string
+	// pusher of new commits, empty if the event did not add commits.
+	pusher string
+	// labels of the pull request.
+	labels []string
+}
+
+// checkTrusted verifies that a pull request can run without approval: it
+// must have the approval label, or its author must be trusted. Either way,
+// the pusher of new commits must be the author or trusted too, and the
+// approval label is removed otherwise.
+func checkTrusted(ctx context.Context, api repoAPI, cfg *pb.PullRequestConfig_CommentConfig, p trustedPush) error {
+	src := commentApprovers(ctx, api, p.org, p.repo, p.number, cfg)
+	label := cfg.GetLabel()
+	approved := label != "" && containsString(p.labels, label)
+	if !approved {
+		if !cfg.GetTrustAuthors() {
+			return Error(codes.FailedPrecondition, "waiting for authorized approval")
+		}
+		ok, err := isTrusted(ctx, api, p.org, p.repo, src, cfg, p.author, p.association)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return Errorf(codes.FailedPrecondition, "waiting for authorized approval of pull request by %s", p.author)
+		}
+	}
+	if p.pusher == "" || strings.EqualFold(p.pusher, p.author) {
+		return nil
+	}
+	// The pusher's association is not reported.
+	ok, err := isTrusted(ctx, api, p.org, p.repo, src, cfg, p.pusher, "")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if approved {
+			if err := api.removeLabel(ctx, p.org, p.repo, p.number, label); err != nil {
+				return Errorf(codes.Unavailable, "error removing label %q: %v", label, err)
+			}
+		}
+		return Errorf(codes.FailedPrecondition, "waiting for authorized approval of push by %s", p.pusher)
+	}
+	return nil
+}
+
+// isTrusted reports whether user, with the given association with the repo,
+// is trusted: either their association is trusted, or they can approve runs.
+func isTrusted(ctx context.Context, api repoAPI, org, repo string, src approverSource, cfg *pb.PullRequestConfig_CommentConfig, user, association string) (bool, error) {
+	associations := cfg.GetTrustedAssociations()
+	if len(associations) == 0 {
+		associations = defaultTrustedAssociations
+	}
+	if association != "" && containsString(associations, association) {
+		return true, nil
+	}
+	err := checkApprover(ctx, api, org, repo, src, user)
+	if serr := new(StatusError); errors.As(err, serr) && serr.Code == codes.PermissionDenied {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// defaultRemoveLabelCommands are the commands that remove the approval label
+// if none are configured.
+var defaultRemoveLabelCommands = []string{"hold"}
+
+// checkRemoveLabel removes the approval label of a pull request if the
+// comment contains one of the commands removing it, and the commenter can
+// approve runs. It returns an error if the label was removed, as the comment
+// must not run the pull request.
+func checkRemoveLabel(ctx context.Context, api repoAPI, cfg *pb.PullRequestConfig_CommentConfig, org, repo string, number int, body, commenter string) error {
+	label := cfg.GetLabel()
+	if label == "" {
+		return nil
+	}
+	names := cfg.GetRemoveLabelCommands()
+	if len(names) == 0 {
+		names = defaultRemoveLabelCommands
+	}
+	cmd := findCommand(parseCommands(body), names)
+	if cmd == nil || (len(cmd.Args) > 0 && strings.EqualFold(cmd.Args[0], "cancel")) {
+		return nil
+	}
+	if err := checkApprover(ctx, api, org, repo, commentApprovers(ctx, api, org, repo, number, cfg), commenter); err != nil {
+		return err
+	}
+	if err := api.removeLabel(ctx, org, repo, number, label); err != nil {
+		return Errorf(codes.Unavailable, "error removing label %q: %v", label, err)
+	}
+	return Errorf(codes.FailedPrecondition, "removed label %q", label)
+}
+
+// addApprovalLabel adds the approval label, if configured, to an approved
+// pull request. Errors are only logged: the approval still runs the pull
+// request, the label only saves approving later pushes again.
+func addApprovalLabel(ctx context.Context, api repoAPI, cfg *pb.PullRequestConfig_CommentConfig, org, repo string, number int) {
+	label := cfg.GetLabel()
+	if label == "" {
+		return
+	}
+	if err := api.addLabel(ctx, org, repo, number, label); err != nil {
+		loggerFrom(ctx).Warnw("error adding approval label", "repo", org+"/"+repo, "number", number, "label", label, "error", err)
+	}
+}
@@ -0,0 +1,206 @@
+package github
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v34/github"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+// SecretSource provides the secrets webhook payloads can be signed with.
+// Several secrets can be active while a secret is rotated.
+type SecretSource interface {
+	Secrets() ([][]byte, error)
+}
+
+// staticSecrets is a fixed list of secrets.
+type staticSecrets [][]byte
+
+func (s staticSecrets) Secrets() ([][]byte, error) {
+	return s, nil
+}
+
+// WithWebhookSecrets validates webhook signatures against the secrets of
+// src, instead of the secret passed to New.
+func WithWebhookSecrets(src SecretSource) Option {
+	return func(s *Server) {
+		s.secrets = src
+	}
+}
+
+// WithSignatureRequired rejects payloads without a X-Hub-Signature-256
+// header. By default only signed payloads are validated.
+func WithSignatureRequired() Option {
+	return func(s *Server) {
+		s.requireSignature = true
+	}
+}
+
+// WithDeliveryCache remembers the payloads of the last size deliveries
+// handled, and rejects deliveries sent again to the same trigger.
+func WithDeliveryCache(size int) Option {
+	return func(s *Server) {
+		if size > 0 {
+			s.deliveries = newDeliveryCache(size)
+		}
+	}
+}
+
+// SecretFile reads webhook secrets from a file, one per line. The file is
+// reloaded when it changes, e.g. when the Kubernetes Secret it is mounted
+// from is updated.
+type SecretFile struct {
+	path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	secrets [][]byte
+}
+
+// NewSecretFile returns a SecretFile reading path, which must exist.
+func NewSecretFile(path string) (*SecretFile, error) {
+	f := &SecretFile{path: path}
+	if _, err := f.Secrets(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Secrets returns the secrets currently in the file.
+func (f *SecretFile) Secrets() ([][]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if f.secrets != nil && fi.ModTime().Equal(f.modTime) && fi.Size() == f.size {
+		return f.secrets, nil
+	}
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return nil, err
+	}
+	secrets := [][]byte{}
+	for _, line := range bytes.Split(b, []byte("\n")) {
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			secrets = append(secrets, line)
+		}
+	}
+	f.secrets, f.modTime, f.size = secrets, fi.ModTime(), fi.Size()
+	return f.secrets, nil
+}
+
+// validateSignature checks that the payload is signed with one of the
+// webhook secrets.
+func (s *Server) validateSignature(headers http.Header, body []byte) error {
+	sig := headers.Get("X-Hub-Signature-256")
+	if sig == "" {
+		if s.requireSignature {
+			return Error(codes.Unauthenticated, "missing X-Hub-Signature-256 header")
+		}
+		return nil
+	}
+	var secrets [][]byte
+	if s.secrets != nil {
+		var err error
+		if secrets, err = s.secrets.Secrets(); err != nil {
+			return Errorf(codes.Unavailable, "error reading webhook secrets: %v", err)
+		}
+	}
+	err := Error(codes.InvalidArgument, "no webhook secret configured")
+	for _, secret := range secrets {
+		if err = github.ValidateSignature(sig, body, secret); err == nil {
+			return nil
+		}
+	}
+	return Errorf(codes.InvalidArgument, "unknown signature: %v", err)
+}
+
+// deliveryHeaders are the headers SCMs send the unique ID of a delivery in.
+var deliveryHeaders = []string{
+	"X-GitHub-Delivery",
+	"X-Gitea-Delivery",
+	"X-Gitlab-Event-UUID",
+	"X-Request-UUID", // Bitbucket Cloud
+	"X-Request-Id",   // Bitbucket Server
+}
+
+// deliveryID returns the ID of a delivery, or "" if the SCM did not send one.
+func deliveryID(header http.Header) string {
+	for _, h := range deliveryHeaders {
+		if id := header.Get(h); id != "" {
+			return id
+		}
+	}
+	return ""
+}
+
+// deliveryKey identifies a delivery handled by a trigger. The same delivery
+// is sent to the interceptor once for every trigger of an EventListener.
+// Deliveries are identified by a hash of their payload, which unlike the
+// delivery ID header is covered by the signature.
+func deliveryKey(in *v1alpha1.InterceptorRequest) string {
+	sum := sha256.Sum256([]byte(in.Body))
+	key := hex.EncodeToString(sum[:])
+	if in.Context != nil {
+		return in.Context.TriggerID + "/" + key
+	}
+	return key
+}
+
+// deliveryCache is a bounded set of delivery keys. The oldest keys are
+// evicted first.
+type deliveryCache struct {
+	size int
+
+	mu    sync.Mutex
+	order *list.List
+	keys  map[string]*list.Element
+}
+
+func newDeliveryCache(size int) *deliveryCache {
+	return &deliveryCache{
+		size:  size,
+		order: list.New(),
+		keys:  make(map[string]*list.Element),
+	}
+}
+
+// add records key, and reports whether it was not already present.
+func (c *deliveryCache) add(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.keys[key]; ok {
+		return false
+	}
+	c.keys[key] = c.order.PushBack(key)
+	if c.order.Len() > c.size {
+		oldest := c.order.Front()
+		c.order.Remove(oldest)
+		delete(c.keys, oldest.Value.(string))
+	}
+	return true
+}
+
+// remove forgets key, so that the delivery can be retried.
+func (c *deliveryCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.keys[key]; ok {
+		c.order.Remove(e)
+		delete(c.keys, key)
+	}
+}
@@ -0,0 +1,193 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	pb "github.com/tektoncd/plumbing/tekton/ci/interceptors/github/pkg/proto/v1alpha1/config_go_proto"
+	"github.com/tektoncd/triggers/pkg/apis/triggers/v1alpha1"
+	"google.golang.org/grpc/codes"
+)
+
+func TestSecretFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	write := func(content string, mtime time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("hunter2\n", now)
+
+	f, err := NewSecretFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets, err := f.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]byte{[]byte("hunter2")}, secrets); diff != "" {
+		t.Error(diff)
+	}
+
+	// Rotating the secret adds a second line.
+	write(" hunter2\n\nhunter3 \n", now.Add(time.Second))
+	secrets, err = f.Secrets()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([][]byte{[]byte("hunter2"), []byte("hunter3")}, secrets); diff != "" {
+		t.Error(diff)
+	}
+
+	if _, err := NewSecretFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestServer_Webhooks(t *testing.T) {
+	body, err := os.ReadFile("testdata/push.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := staticSecrets{[]byte("hunter2"), []byte("hunter3")}
+	request := func(sig, delivery, trigger string) *v1alpha1.InterceptorRequest {
+		req := &v1alpha1.InterceptorRequest{
+			Body: string(body),
+			Header: map[string][]string{
+				"X-Github-Event": {"push"},
+			},
+			InterceptorParams: map[string]interface{}{
+				"config": &pb.Config{Push: &pb.PushConfig{}},
+			},
+			Context: &v1alpha1.TriggerContext{TriggerID: trigger},
+		}
+		if sig != "" {
+			req.Header["X-Hub-Signature-256"] = []string{sig}
+		}
+		if delivery != "" {
+			req.Header["X-Github-Delivery"] = []string{delivery}
+		}
+		return req
+	}
+
+	for _, tc := range []struct {
+		name string
+		opts []Option
+		reqs []*v1alpha1.InterceptorRequest
+		// want is the status code of the last request.
+		want codes.Code
+	}{
+		{
+			name: "new secret",
+			opts: []Option{WithWebhookSecrets(secrets)},
+			reqs: []*v1alpha1.InterceptorRequest{request(signature(body, []byte("hunter3")), "", "")},
+		},
+		{
+			name: "unknown secret",
+			opts: []Option{WithWebhookSecrets(secrets)},
+			reqs: []*v1alpha1.InterceptorRequest{request(signature(body, []byte("hunter1")), "", "")},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "unsigned",
+			opts: []Option{WithWebhookSecrets(secrets)},
+			reqs: []*v1alpha1.InterceptorRequest{request("", "", "")},
+		},
+		{
+			name: "unsigned rejected",
+			opts: []Option{WithWebhookSecrets(secrets), WithSignatureRequired()},
+			reqs: []*v1alpha1.InterceptorRequest{request("", "", "")},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "replay",
+			opts: []Option{WithDeliveryCache(10)},
+			reqs: []*v1alpha1.InterceptorRequest{
+				request("", "72d3162e", "push-trigger"),
+				request("", "72d3162e", "push-trigger"),
+			},
+			want: codes.AlreadyExists,
+		},
+		{
+			name: "replay with another delivery ID",
+			opts: []Option{WithDeliveryCache(10)},
+			reqs: []*v1alpha1.InterceptorRequest{
+				request("", "72d3162e", "push-trigger"),
+				request("", "a1b2c3d4", "push-trigger"),
+			},
+			want: codes.AlreadyExists,
+		},
+		{
+			name: "signed delivery without ID",
+			opts: []Option{WithDeliveryCache(10), WithWebhookSecrets(secrets)},
+			reqs: []*v1alpha1.InterceptorRequest{request(signature(body, []byte("hunter2")), "", "push-trigger")},
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "same delivery for another trigger",
+			opts: []Option{WithDeliveryCache(10)},
+			reqs: []*v1alpha1.InterceptorRequest{
+				request("", "72d3162e", "push-trigger"),
+				request("", "72d3162e", "other-trigger"),
+			},
+		},
+		{
+			name: "evicted delivery",
+			opts: []Option{WithDeliveryCache(1)},
+			reqs: []*v1alpha1.InterceptorRequest{
+				request("", "72d3162e", "push-trigger"),
+				func() *v1alpha1.InterceptorRequest {
+					req := request("", "a1b2c3d4", "push-trigger")
+					req.Body = strings.Replace(req.Body, "simple-tag", "other-tag", 1)
+					return req
+				}(),
+				request("", "72d3162e", "push-trigger"),
+			},
+		},
+		{
+			name: "failed delivery can be retried",
+			opts: []Option{WithDeliveryCache(10), WithWebhookSecrets(secrets)},
+			reqs: []*v1alpha1.InterceptorRequest{
+				func() *v1alpha1.InterceptorRequest {
+					req := request("", "72d3162e", "push-trigger")
+					req.Body = `{"after": "` + zeroSHA + `"}`
+					return req
+				}(),
+				request("", "72d3162e", "push-trigger"),
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := New(http.DefaultClient, nil, tc.opts...)
+			var resp v1alpha1.InterceptorResponse
+			for _, req := range tc.reqs {
+				b := new(bytes.Buffer)
+				if err := json.NewEncoder(b).Encode(req); err != nil {
+					t.Fatal(err)
+				}
+				rw := httptest.NewRecorder()
+				s.ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/", b))
+				resp = v1alpha1.InterceptorResponse{}
+				if err := json.NewDecoder(rw.Body).Decode(&resp); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if resp.Status.Code != tc.want {
+				t.Errorf("want code %v, got %+v", tc.want, resp.Status)
+			}
+		})
+	}
+}